@@ -0,0 +1,16 @@
+package app
+
+import (
+	"cron-shot/config"
+	"cron-shot/utils"
+	"image"
+)
+
+// IsBlankFrame 按配置的容差判断截图是否为空白帧（纯色、近零方差或大部分透明）
+// 未启用空白帧检测时始终返回 false
+func IsBlankFrame(img *image.RGBA, cfg config.AppConfig) (bool, string) {
+	if !cfg.BlankDetectEnabled {
+		return false, ""
+	}
+	return utils.DetectBlankFrame(img, utils.BlankOptionsFromTolerance(cfg.BlankTolerance))
+}
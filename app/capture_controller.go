@@ -2,26 +2,61 @@ package app
 
 import (
 	"cron-shot/config"
+	"cron-shot/constants"
 	"cron-shot/logging"
 	"cron-shot/sys_utils"
+	"fmt"
 	"image"
+	"sync"
 	"time"
-
-	"github.com/lxn/win"
 )
 
 // AutoCaptureController 负责根据配置周期性截取当前进程的窗口并保存
 // 通过回调获取当前进程名与规则集合，内部使用定时器驱动循环
+// Config 为配置来源，运行中修改截图周期会立即生效而无需重启；Backend 为截图后端，由构造函数设置为平台默认实现，运行中通过 SetBackend 切换；
+// GetScreenRules 返回独立屏幕规则；Idle/IdlePolicy 用于在用户空闲或锁屏时暂停截图，状态变化通过 OnPauseChanged 通知
 type AutoCaptureController struct {
 	stopChan       chan struct{}
+	cancelSub      func()
+	Config         config.Provider
 	CurrentProcess func() string
-	GetRules       func() []config.AppRule
+	GetRuleSet     func() *RuleSet
+	GetScreenRules func() []config.ScreenRule
+	Backend        sys_utils.CaptureBackend
+	backendMu      sync.Mutex
+	Idle           IdleDetector
+	IdlePolicy     func() IdlePolicy
+	OnPauseChanged func(paused bool, reason string)
+	pauseMu        sync.Mutex
+	paused         bool
 }
 
 // NewAutoCaptureController 创建控制器
-// curr: 返回当前选择的进程名；rules: 返回最新规则列表
-func NewAutoCaptureController(curr func() string, rules func() []config.AppRule) *AutoCaptureController {
-	return &AutoCaptureController{CurrentProcess: curr, GetRules: rules}
+// cfg: 配置来源；curr: 返回当前选择的进程名；rules: 返回最新的已编译规则集合（通常为 RuleSetSource(cfg)）
+func NewAutoCaptureController(cfg config.Provider, curr func() string, rules func() *RuleSet) *AutoCaptureController {
+	return &AutoCaptureController{
+		Config:         cfg,
+		CurrentProcess: curr,
+		GetRuleSet:     rules,
+		GetScreenRules: func() []config.ScreenRule { return cfg.Snapshot().ScreenRules },
+		Backend:        sys_utils.DefaultCaptureBackend(),
+		Idle:           NewSystemIdleDetector(),
+		IdlePolicy:     func() IdlePolicy { return IdlePolicyFrom(cfg.Snapshot()) },
+	}
+}
+
+// IdlePolicyFrom 从配置构造空闲暂停策略
+func IdlePolicyFrom(c config.AppConfig) IdlePolicy {
+	return IdlePolicy{
+		Enabled:     c.IdlePauseEnabled,
+		IdleAfter:   time.Duration(c.IdlePauseMinutes) * time.Minute,
+		PauseOnLock: c.PauseOnLockEnabled,
+	}
+}
+
+// config 返回当前配置的副本
+func (c *AutoCaptureController) config() config.AppConfig {
+	return c.Config.Snapshot()
 }
 
 // Start 启动自动截图循环
@@ -30,41 +65,128 @@ func (c *AutoCaptureController) Start() {
 		return
 	}
 	c.stopChan = make(chan struct{})
+	// 截图周期变化时通知循环重建定时器；存储路径等其它设置在每次截图时读取
+	reset := make(chan struct{}, 1)
+	c.cancelSub = config.OnChange(c.Config, config.ChangeInterval|config.ChangeStorageRoot, func(changed config.Change, _, cur config.AppConfig) {
+		if changed.Has(config.ChangeInterval) {
+			select {
+			case reset <- struct{}{}:
+			default:
+			}
+		}
+		if changed.Has(config.ChangeStorageRoot) {
+			logging.Info("auto capture storage root changed: " + cur.StorageRoot)
+		}
+	})
 	// 启动后台 goroutine 执行周期任务
-	go c.loop(c.stopChan)
+	go c.loop(c.stopChan, reset)
 }
 
-// Stop 停止自动截图循环
+// Stop 停止自动截图循环；若处于暂停状态则同时通知恢复
 func (c *AutoCaptureController) Stop() {
 	if c.stopChan != nil {
+		if c.cancelSub != nil {
+			c.cancelSub()
+			c.cancelSub = nil
+		}
 		close(c.stopChan)
 		c.stopChan = nil
+		// 记录本次运行期间各截图策略的统计
+		if m, ok := c.backend().(sys_utils.CaptureMetricsReporter); ok {
+			logging.Info("capture metrics: " + m.MetricsSummary())
+		}
 	}
+	c.setPaused(false, "")
 }
 
-// loop 定时驱动自动截图；收到 stop 信号后退出
-func (c *AutoCaptureController) loop(stop chan struct{}) {
-	defer logging.RecoverPanic("AutoCaptureController.loop")
-	// 读取截图间隔（秒）；非法值时回退到 1s
-	interval := time.Duration(config.GetScreenshotIntervalSec()) * time.Second
+// Paused 返回当前是否因空闲/锁屏而暂停
+func (c *AutoCaptureController) Paused() bool {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	return c.paused
+}
+
+// setPaused 更新暂停状态；仅在状态切换时记录日志并触发回调
+func (c *AutoCaptureController) setPaused(paused bool, reason string) {
+	c.pauseMu.Lock()
+	if c.paused == paused {
+		c.pauseMu.Unlock()
+		return
+	}
+	c.paused = paused
+	c.pauseMu.Unlock()
+	if paused {
+		logging.Info("auto capture paused: " + reason)
+	} else {
+		logging.Info("auto capture resumed")
+	}
+	if c.OnPauseChanged != nil {
+		c.OnPauseChanged(paused, reason)
+	}
+}
+
+// interval 返回配置的截图间隔；非法值时回退到 1s
+func (c *AutoCaptureController) interval() time.Duration {
+	interval := time.Duration(c.config().ScreenshotIntervalSec) * time.Second
 	if interval <= 0 {
 		interval = time.Second
 	}
+	return interval
+}
+
+// loop 定时驱动自动截图；收到 reset 信号时按新的截图间隔重置定时器，收到 stop 信号后退出
+func (c *AutoCaptureController) loop(stop, reset chan struct{}) {
+	defer logging.RecoverPanic("AutoCaptureController.loop")
 	// 使用 Ticker 周期触发截图
+	interval := c.interval()
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-stop:
 			return
+		case <-reset:
+			if d := c.interval(); d != interval {
+				interval = d
+				ticker.Reset(interval)
+				logging.Info("auto capture interval changed: " + interval.String())
+			}
 		case <-ticker.C:
+			// 空闲/锁屏时跳过本次截图，检测到活动后自动恢复
+			if c.checkIdle() {
+				continue
+			}
 			c.runOnce()
 		}
 	}
 }
 
+// checkIdle 按空闲策略更新暂停状态，返回本次是否应跳过截图
+func (c *AutoCaptureController) checkIdle() bool {
+	if c.IdlePolicy == nil {
+		return false
+	}
+	paused, reason := c.IdlePolicy().Evaluate(c.Idle)
+	c.setPaused(paused, reason)
+	return paused
+}
+
 // runOnce 执行一次完整的截图与保存流程
 func (c *AutoCaptureController) runOnce() {
+	base := time.Now()
+	idx := 0
+	// 同一轮中相同屏幕区域只截取一次（多个窗口命中同一显示器规则时）
+	seen := map[string]bool{}
+	// 独立屏幕规则不依赖进程选择
+	if c.GetScreenRules != nil {
+		for _, sr := range c.GetScreenRules() {
+			if !sr.Enabled {
+				continue
+			}
+			c.captureScreenAndSave(constants.TextScreenFolder, "", sr.Name, nil, targetOfScreenRule(sr), base.Add(time.Duration(idx)*time.Millisecond), seen)
+			idx++
+		}
+	}
 	// 从回调获取当前进程名；为空则跳过
 	proc := ""
 	if c.CurrentProcess != nil {
@@ -75,56 +197,141 @@ func (c *AutoCaptureController) runOnce() {
 	}
 	logging.Info("start screenshot tick for process: " + proc)
 	// 枚举该进程的可见窗口（标题+句柄）
-	infos, err := sys_utils.GetProcessWindowsDetailed(proc)
+	infos, err := c.backend().ListWindows(proc)
 	if err != nil || len(infos) == 0 {
 		return
 	}
-	// 读取规则列表用于匹配
-	rules := []config.AppRule{}
-	if c.GetRules != nil {
-		rules = c.GetRules()
+	// 读取已编译的规则集合用于匹配（规则未变化时不会重新编译）
+	set := CompileRules(nil)
+	if c.GetRuleSet != nil {
+		set = c.GetRuleSet()
 	}
-	base := time.Now()
-	idx := 0
+	b := c.backend()
 	for _, info := range infos {
 		title := info.Title
-		// 先用窗口标题执行规则匹配（优先文本等价，其次正则）
-		rule, ok := MatchRule(title, rules)
-		if !ok {
-			continue
-		}
-		// 执行截图与保存；索引用于微调多窗口的时间戳
-		if img, path := c.captureAndSave(proc, info, rule, base.Add(time.Duration(idx)*time.Millisecond)); img != nil {
-			_ = path
+		// 按优先级与扩展条件匹配；Continue 规则可使同一窗口保存到多个目标
+		matched := set.MatchAll(info, b)
+		// 窗口只截取一次，供命中的各规则共用
+		var img *image.RGBA
+		captured := false
+		for _, rule := range matched {
+			// 执行截图与保存；索引用于微调多窗口的时间戳
+			ts := base.Add(time.Duration(idx) * time.Millisecond)
+			folder, fixed := set.ResolveFolder(title, rule)
+			if t := targetOfRule(rule); t.Kind != config.TargetWindow {
+				w := info
+				c.captureScreenAndSave(proc, fixed, folder, &w, t, ts, seen)
+				idx++
+				continue
+			}
+			key := proc + "|" + fixed + "|" + folder + "|" + fmt.Sprintf("window:%d", info.Handle)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if !captured {
+				img, captured = c.captureWindow(info), true
+			}
+			if img == nil {
+				continue
+			}
+			c.save(img, proc, fixed, folder, ts, windowMeta(b, proc, info))
+			idx++
 		}
-		idx++
 	}
 }
 
-// captureAndSave 对单个窗口执行截图、去重判断与保存
-func (c *AutoCaptureController) captureAndSave(proc string, info sys_utils.WindowInfo, rule *config.AppRule, t time.Time) (*image.RGBA, string) {
+// backend 返回截图后端；未注入时使用平台默认实现
+func (c *AutoCaptureController) backend() sys_utils.CaptureBackend {
+	c.backendMu.Lock()
+	defer c.backendMu.Unlock()
+	return c.Backend
+}
+
+// SetBackend 切换截图后端（如配置的 capture_backend 变化），从下一次截图起生效
+func (c *AutoCaptureController) SetBackend(b sys_utils.CaptureBackend) {
+	c.backendMu.Lock()
+	c.Backend = b
+	c.backendMu.Unlock()
+}
+
+// captureWindow 对单个窗口执行截图与空白帧检测；不可截取、失败或空白时返回 nil
+func (c *AutoCaptureController) captureWindow(info sys_utils.WindowInfo) *image.RGBA {
+	b := c.backend()
 	// 跳过最小化或不可见窗口，避免空白截图
-	if win.IsIconic(info.HWND) || !win.IsWindowVisible(info.HWND) {
-		return nil, ""
+	if !b.WindowCapturable(info) {
+		return nil
 	}
-	// 使用 PrintWindow 渲染窗口至位图
-	img, err := sys_utils.CaptureWindowImage(info.HWND)
+	// 通过截图后端渲染窗口至位图（Windows 下为 PrintWindow 及其回退策略）
+	img, err := b.CaptureWindow(info)
 	if err != nil {
 		logging.Error("capture failed: " + err.Error())
-		return nil, ""
+		return nil
 	}
-	// 解析存储文件夹并进行去重判断
-	folder, fixed := ResolveFolder(info.Title, rule)
-	if ShouldSkipDueToDedupe(img, config.GetStorageRoot(), proc, fixed, folder) {
+	// 空白帧检测在去重之前执行：按配置换用其它截图方式重试，或跳过并记录
+	cfg := c.config()
+	if blank, reason := IsBlankFrame(img, cfg); blank {
+		alt, ok := b.(sys_utils.AlternateCapturer)
+		if cfg.BlankAction != config.BlankActionRetry || !ok {
+			logging.Info("skip blank frame (" + reason + "): " + info.Title)
+			return nil
+		}
+		img, err = alt.CaptureWindowAlternate(info, func(i *image.RGBA) bool {
+			blank, _ := IsBlankFrame(i, cfg)
+			return blank
+		})
+		if err != nil {
+			logging.Info("skip blank frame (" + reason + "), retry failed: " + err.Error())
+			return nil
+		}
+		logging.Info("blank frame (" + reason + ") recovered by alternate capture: " + info.Title)
+	}
+	return img
+}
+
+// captureScreenAndSave 截取显示器/区域目标并保存；w 为触发截图的窗口（可为空）
+func (c *AutoCaptureController) captureScreenAndSave(proc, fixed, folder string, w *sys_utils.WindowInfo, target captureTarget, t time.Time, seen map[string]bool) {
+	b := c.backend()
+	monitorIndex := -1
+	if target.Kind == config.TargetMonitor {
+		idx, err := resolveMonitor(b, target, w)
+		if err != nil {
+			logging.Error("resolve monitor failed: " + err.Error())
+			return
+		}
+		monitorIndex = idx
+	}
+	key := proc + "|" + fixed + "|" + folder + "|" + target.key(monitorIndex)
+	if seen[key] {
+		return
+	}
+	seen[key] = true
+	img, err := captureScreenTarget(b, target, monitorIndex)
+	if err != nil {
+		logging.Error("capture " + target.Kind + " failed: " + err.Error())
+		return
+	}
+	// 屏幕目标没有其它截图方式可换，空白帧直接跳过
+	if blank, reason := IsBlankFrame(img, c.config()); blank {
+		logging.Info("skip blank frame (" + reason + "): " + target.Kind)
+		return
+	}
+	c.save(img, proc, fixed, folder, t, map[string]string{MetaProcess: proc, MetaTarget: target.Kind})
+}
+
+// save 执行去重判断并保存截图（meta 写入 PNG 文本块），返回保存路径（跳过或失败时为空）
+func (c *AutoCaptureController) save(img *image.RGBA, proc, fixed, folder string, t time.Time, meta map[string]string) string {
+	cfg := c.config()
+	if ShouldSkipDueToDedupe(img, cfg, proc, fixed, folder) {
 		logging.Info("skip save due to dedupe")
-		return img, ""
+		return ""
 	}
 	// 保存截图到目标目录
-	p, err := sys_utils.SaveCronShot(img, config.GetStorageRoot(), proc, fixed, folder, t)
+	p, err := sys_utils.SaveCronShotWithMeta(img, cfg.StorageRoot, proc, fixed, folder, t, meta)
 	if err != nil {
 		logging.Error("save failed: " + err.Error())
-		return img, ""
+		return ""
 	}
 	logging.Info("screenshot saved: " + p)
-	return img, p
+	return p
 }
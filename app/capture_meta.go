@@ -0,0 +1,39 @@
+package app
+
+import (
+	"cron-shot/sys_utils"
+	"fmt"
+	"strconv"
+)
+
+// PNG 文本块中使用的元数据键
+const (
+	MetaProcess  = "CronShot:Process"
+	MetaTitle    = "CronShot:Title"
+	MetaTarget   = "CronShot:Target"
+	MetaDPI      = "CronShot:DPI"
+	MetaScale    = "CronShot:Scale"
+	MetaBounds   = "CronShot:Bounds"
+	MetaStrategy = "CronShot:Strategy"
+)
+
+// windowMeta 构造窗口截图的元数据；后端支持时附带 DPI 缩放与可见边框
+func windowMeta(b sys_utils.CaptureBackend, proc string, info sys_utils.WindowInfo) map[string]string {
+	meta := map[string]string{MetaProcess: proc, MetaTitle: info.Title}
+	p, ok := b.(sys_utils.CaptureMetaProvider)
+	if !ok {
+		return meta
+	}
+	m := p.CaptureMeta(info)
+	if m.DPI > 0 {
+		meta[MetaDPI] = strconv.Itoa(int(m.DPI))
+		meta[MetaScale] = strconv.FormatFloat(m.Scale, 'f', 2, 64)
+	}
+	if !m.Bounds.Empty() {
+		meta[MetaBounds] = fmt.Sprintf("%d,%d,%d,%d", m.Bounds.Min.X, m.Bounds.Min.Y, m.Bounds.Dx(), m.Bounds.Dy())
+	}
+	if m.Strategy != "" {
+		meta[MetaStrategy] = m.Strategy
+	}
+	return meta
+}
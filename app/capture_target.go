@@ -0,0 +1,99 @@
+package app
+
+import (
+	"cron-shot/config"
+	"cron-shot/sys_utils"
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// captureTarget 描述一次截图的目标：窗口、单显示器、全部显示器或固定区域
+type captureTarget struct {
+	Kind    string
+	Monitor int // 从 1 开始；0 表示跟随窗口所在显示器（无窗口时为主显示器）
+	Rect    *config.CaptureRect
+}
+
+// targetOfRule 返回窗口规则的截图目标
+func targetOfRule(r *config.AppRule) captureTarget {
+	if r == nil {
+		return captureTarget{Kind: config.TargetWindow}
+	}
+	return captureTarget{Kind: normalizeTarget(r.Target), Monitor: r.Monitor, Rect: r.Rect}
+}
+
+// targetOfScreenRule 返回屏幕规则的截图目标
+func targetOfScreenRule(r config.ScreenRule) captureTarget {
+	return captureTarget{Kind: normalizeTarget(r.Target), Monitor: r.Monitor, Rect: r.Rect}
+}
+
+// normalizeTarget 统一目标类型书写；空值视为 window
+func normalizeTarget(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if t == "" {
+		return config.TargetWindow
+	}
+	return t
+}
+
+// key 返回目标的唯一标识，用于同一轮中避免重复截取同一屏幕区域
+// 跟随窗口的显示器目标需要先解析为具体序号，因此由调用方传入已解析的序号
+func (t captureTarget) key(monitorIndex int) string {
+	switch t.Kind {
+	case config.TargetMonitor:
+		return fmt.Sprintf("monitor:%d", monitorIndex)
+	case config.TargetAllMonitors:
+		return "all"
+	case config.TargetRect:
+		if t.Rect != nil {
+			return fmt.Sprintf("rect:%d,%d,%d,%d", t.Rect.X, t.Rect.Y, t.Rect.Width, t.Rect.Height)
+		}
+	}
+	return ""
+}
+
+// resolveMonitor 将目标中的显示器序号解析为 0 起始的枚举下标
+func resolveMonitor(b sys_utils.CaptureBackend, t captureTarget, w *sys_utils.WindowInfo) (int, error) {
+	if t.Monitor > 0 {
+		return t.Monitor - 1, nil
+	}
+	mons, err := b.Monitors()
+	if err != nil {
+		return -1, err
+	}
+	if w != nil {
+		bounds := b.WindowBounds(*w)
+		center := image.Pt((bounds.Min.X+bounds.Max.X)/2, (bounds.Min.Y+bounds.Max.Y)/2)
+		if idx := sys_utils.MonitorIndexAt(mons, center); idx >= 0 {
+			return idx, nil
+		}
+	}
+	for _, m := range mons {
+		if m.Primary {
+			return m.Index, nil
+		}
+	}
+	if len(mons) == 0 {
+		return -1, errors.New("no monitors found")
+	}
+	return 0, nil
+}
+
+// captureScreenTarget 截取非窗口目标；monitorIndex 为 resolveMonitor 的结果
+func captureScreenTarget(b sys_utils.CaptureBackend, t captureTarget, monitorIndex int) (*image.RGBA, error) {
+	switch t.Kind {
+	case config.TargetMonitor:
+		return sys_utils.CaptureMonitor(b, monitorIndex)
+	case config.TargetAllMonitors:
+		return sys_utils.CaptureAllMonitors(b)
+	case config.TargetRect:
+		if t.Rect == nil || t.Rect.Width <= 0 || t.Rect.Height <= 0 {
+			return nil, errors.New("capture rect not configured")
+		}
+		r := image.Rect(t.Rect.X, t.Rect.Y, t.Rect.X+t.Rect.Width, t.Rect.Y+t.Rect.Height)
+		return b.CaptureRect(r)
+	}
+	return nil, fmt.Errorf("unknown capture target: %s", t.Kind)
+}
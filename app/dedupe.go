@@ -2,36 +2,28 @@ package app
 
 import (
 	"cron-shot/config"
+	"cron-shot/sys_utils"
 	"cron-shot/utils"
 	"image"
-	"path/filepath"
-	"strings"
+	"time"
 )
 
-// ShouldSkipDueToDedupe 根据阈值判断是否跳过保存（去重）
+// ShouldSkipDueToDedupe 根据配置的去重开关与阈值判断是否跳过保存（去重）
 // - 当阈值=100时执行像素级全等比较
 // - 否则使用 AHash16x16 + 汉明距离计算相似度
-func ShouldSkipDueToDedupe(img *image.RGBA, storageRoot, processName, fixed, folder string) bool {
+func ShouldSkipDueToDedupe(img *image.RGBA, cfg config.AppConfig, processName, fixed, folder string) bool {
 	// 去重开关关闭则直接保存
-	if !config.GetDedupeEnabled() {
+	if !cfg.DedupeEnabled {
 		return false
 	}
-	// 构造目标目录：process/fixed/folder 或 process/folder
-	proc := utils.SanitizeProcessName(processName)
-	sub := utils.SanitizeFolderName(folder)
-	var dir string
-	if strings.TrimSpace(fixed) != "" {
-		fix := utils.SanitizeFolderName(fixed)
-		dir = filepath.Join(storageRoot, proc, fix, sub)
-	} else {
-		dir = filepath.Join(storageRoot, proc, sub)
-	}
+	// 目标目录与保存时一致：process/fixed/folder 或 process/folder
+	dir, _ := sys_utils.CronShotPath(cfg.StorageRoot, processName, fixed, folder, time.Time{})
 	// 读取最近一张图片；无历史则不跳过
 	prevImg, _ := utils.LatestPNGImage(dir)
 	if prevImg == nil {
 		return false
 	}
-	th := config.GetDedupeThreshold()
+	th := cfg.DedupeThreshold
 	if th >= 100 {
 		// 阈值满分：执行像素级比较
 		return utils.ImagesEqualExact(img, prevImg)
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"cron-shot/constants"
+	"cron-shot/sys_utils"
+	"time"
+)
+
+// IdleDetector 抽象用户活动状态的检测（空闲时长、锁屏、屏保）
+// 系统实现基于 Win32 API；测试中可替换为固定状态的实现
+type IdleDetector interface {
+	IdleDuration() time.Duration
+	SessionLocked() bool
+	ScreenSaverRunning() bool
+}
+
+// systemIdleDetector 使用系统 API 检测用户活动状态
+type systemIdleDetector struct{}
+
+// NewSystemIdleDetector 创建基于系统 API 的空闲检测器
+func NewSystemIdleDetector() IdleDetector { return systemIdleDetector{} }
+
+func (systemIdleDetector) IdleDuration() time.Duration {
+	d, err := sys_utils.GetIdleDuration()
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+func (systemIdleDetector) SessionLocked() bool      { return sys_utils.IsSessionLocked() }
+func (systemIdleDetector) ScreenSaverRunning() bool { return sys_utils.IsScreenSaverRunning() }
+
+// IdlePolicy 描述何时暂停自动截图
+// Enabled: 总开关；IdleAfter: 无输入超过该时长即暂停（<=0 表示不按空闲时长判断）；
+// PauseOnLock: 锁屏或屏保运行时暂停
+type IdlePolicy struct {
+	Enabled     bool
+	IdleAfter   time.Duration
+	PauseOnLock bool
+}
+
+// Evaluate 根据检测器状态判断是否应暂停，返回是否暂停与原因文本
+func (p IdlePolicy) Evaluate(d IdleDetector) (bool, string) {
+	if !p.Enabled || d == nil {
+		return false, ""
+	}
+	if p.PauseOnLock {
+		if d.SessionLocked() {
+			return true, constants.TextPauseReasonLocked
+		}
+		if d.ScreenSaverRunning() {
+			return true, constants.TextPauseReasonScreenSaver
+		}
+	}
+	if p.IdleAfter > 0 && d.IdleDuration() >= p.IdleAfter {
+		return true, constants.TextPauseReasonIdle
+	}
+	return false, ""
+}
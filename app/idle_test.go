@@ -0,0 +1,103 @@
+package app
+
+import (
+	"cron-shot/constants"
+	"testing"
+	"time"
+)
+
+// fakeIdle 固定状态的空闲检测器
+type fakeIdle struct {
+	idle        time.Duration
+	locked      bool
+	screenSaver bool
+}
+
+func (f *fakeIdle) IdleDuration() time.Duration { return f.idle }
+func (f *fakeIdle) SessionLocked() bool         { return f.locked }
+func (f *fakeIdle) ScreenSaverRunning() bool    { return f.screenSaver }
+
+func TestIdlePolicyEvaluate(t *testing.T) {
+	on := IdlePolicy{Enabled: true, IdleAfter: 10 * time.Minute, PauseOnLock: true}
+	tests := []struct {
+		name       string
+		policy     IdlePolicy
+		state      fakeIdle
+		wantPaused bool
+		wantReason string
+	}{
+		{"active", on, fakeIdle{idle: time.Minute}, false, ""},
+		{"idle over threshold", on, fakeIdle{idle: 11 * time.Minute}, true, constants.TextPauseReasonIdle},
+		{"idle at threshold", on, fakeIdle{idle: 10 * time.Minute}, true, constants.TextPauseReasonIdle},
+		{"locked", on, fakeIdle{locked: true}, true, constants.TextPauseReasonLocked},
+		{"screensaver", on, fakeIdle{screenSaver: true}, true, constants.TextPauseReasonScreenSaver},
+		{"locked takes precedence", on, fakeIdle{idle: time.Hour, locked: true, screenSaver: true}, true, constants.TextPauseReasonLocked},
+		{"disabled", IdlePolicy{IdleAfter: time.Minute, PauseOnLock: true}, fakeIdle{idle: time.Hour, locked: true}, false, ""},
+		{"lock ignored without pause on lock", IdlePolicy{Enabled: true, IdleAfter: 10 * time.Minute}, fakeIdle{locked: true, screenSaver: true}, false, ""},
+		{"idle ignored without threshold", IdlePolicy{Enabled: true, PauseOnLock: true}, fakeIdle{idle: time.Hour}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			paused, reason := tt.policy.Evaluate(&state)
+			if paused != tt.wantPaused || reason != tt.wantReason {
+				t.Errorf("Evaluate = (%v, %q), want (%v, %q)", paused, reason, tt.wantPaused, tt.wantReason)
+			}
+		})
+	}
+	if paused, _ := on.Evaluate(nil); paused {
+		t.Errorf("Evaluate(nil) paused")
+	}
+}
+
+// TestIdlePauseResume 每个周期按检测器状态暂停，检测到活动后自动恢复，仅在状态切换时通知
+func TestIdlePauseResume(t *testing.T) {
+	state := &fakeIdle{}
+	var events []string
+	c := &AutoCaptureController{
+		Idle:       state,
+		IdlePolicy: func() IdlePolicy { return IdlePolicy{Enabled: true, IdleAfter: 5 * time.Minute, PauseOnLock: true} },
+		OnPauseChanged: func(paused bool, reason string) {
+			if paused {
+				events = append(events, "pause:"+reason)
+			} else {
+				events = append(events, "resume")
+			}
+		},
+	}
+	steps := []struct {
+		state    fakeIdle
+		wantSkip bool
+	}{
+		{fakeIdle{idle: time.Minute}, false},
+		{fakeIdle{idle: 6 * time.Minute}, true},
+		{fakeIdle{idle: 7 * time.Minute}, true},
+		{fakeIdle{idle: time.Second}, false},
+		{fakeIdle{locked: true}, true},
+		{fakeIdle{}, false},
+	}
+	for i, s := range steps {
+		*state = s.state
+		if skip := c.checkIdle(); skip != s.wantSkip {
+			t.Errorf("step %d: skip = %v, want %v", i, skip, s.wantSkip)
+		}
+	}
+	want := []string{
+		"pause:" + constants.TextPauseReasonIdle,
+		"resume",
+		"pause:" + constants.TextPauseReasonLocked,
+		"resume",
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events = %q, want %q", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("events = %q, want %q", events, want)
+			break
+		}
+	}
+	if c.Paused() {
+		t.Errorf("still paused after activity")
+	}
+}
@@ -13,6 +13,8 @@ type ProcessWindowController struct {
 	stopChan         chan struct{}
 	mutex            sync.Mutex
 	OnWindowsUpdated func([]string)
+	windowsMu        sync.Mutex
+	windows          []string
 }
 
 // NewProcessWindowController 创建进程窗口控制器
@@ -38,6 +40,9 @@ func (c *ProcessWindowController) SetProcess(processName string) {
 	// 更新当前选择并立即刷新一次窗口列表
 	c.selectedProcess = processName
 	if processName == "" {
+		c.windowsMu.Lock()
+		c.windows = nil
+		c.windowsMu.Unlock()
 		if c.OnWindowsUpdated != nil {
 			c.OnWindowsUpdated([]string{})
 		}
@@ -74,11 +79,21 @@ func (c *ProcessWindowController) updateWindows() {
 	}
 	// 获取窗口标题列表
 	windows, _ := sys_utils.GetProcessWindows(c.selectedProcess)
+	c.windowsMu.Lock()
+	c.windows = windows
+	c.windowsMu.Unlock()
 	if c.OnWindowsUpdated != nil {
 		c.OnWindowsUpdated(windows)
 	}
 }
 
+// Windows 返回最近一次刷新得到的窗口标题副本
+func (c *ProcessWindowController) Windows() []string {
+	c.windowsMu.Lock()
+	defer c.windowsMu.Unlock()
+	return append([]string(nil), c.windows...)
+}
+
 // Stop 停止后台轮询（若存在）
 func (c *ProcessWindowController) Stop() {
 	c.mutex.Lock()
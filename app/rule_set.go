@@ -0,0 +1,136 @@
+package app
+
+import (
+	"cron-shot/config"
+	"cron-shot/constants"
+	"cron-shot/logging"
+	"cron-shot/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dlclark/regexp2"
+)
+
+// RuleSet 已编译的规则集合：规则变化时构建一次，供截图、状态高亮与界面校验共用
+// 所有正则均通过 utils.CompileRegex 编译（同一引擎、带匹配超时）；非法表达式视为不匹配
+// 所属规则组未生效的规则在匹配时跳过（按匹配时刻判断，时间表无需重新编译）
+type RuleSet struct {
+	rules    []config.AppRule
+	groups   []config.RuleGroup
+	compiled []*compiledRule // 已启用规则，按优先级从高到低稳定排序
+	byRule   map[*config.AppRule]*compiledRule
+}
+
+// compiledRule 单条规则及其预编译的正则
+type compiledRule struct {
+	rule    *config.AppRule
+	title   *regexp2.Regexp
+	class   *regexp2.Regexp
+	exePath *regexp2.Regexp
+	cmdLine *regexp2.Regexp
+	parent  *regexp2.Regexp
+	storage *regexp2.Regexp
+	// storageErr 存储规则非法时为 true，解析文件夹返回未知名称
+	storageErr bool
+}
+
+// CompileRules 编译规则列表；编译失败的表达式记录日志后按不匹配处理
+func CompileRules(rules []config.AppRule) *RuleSet {
+	return CompileRulesWithGroups(rules, nil)
+}
+
+// CompileRulesWithGroups 编译规则列表，并按 groups 判断规则所属组是否生效
+func CompileRulesWithGroups(rules []config.AppRule, groups []config.RuleGroup) *RuleSet {
+	s := &RuleSet{
+		rules:  append([]config.AppRule(nil), rules...),
+		groups: append([]config.RuleGroup(nil), groups...),
+		byRule: map[*config.AppRule]*compiledRule{},
+	}
+	compile := func(pattern string) *regexp2.Regexp {
+		if pattern == "" {
+			return nil
+		}
+		re, err := utils.CompileRegex(pattern)
+		if err != nil {
+			logging.Error("invalid rule pattern " + pattern + ": " + err.Error())
+		}
+		return re
+	}
+	for i := range s.rules {
+		r := &s.rules[i]
+		c := &compiledRule{rule: r, title: compile(r.Pattern)}
+		if m := r.Match; m != nil {
+			c.class = compile(m.Class)
+			c.exePath = compile(m.ExePath)
+			c.cmdLine = compile(m.CmdLine)
+			c.parent = compile(m.Parent)
+		}
+		if sr := strings.TrimSpace(r.StorageRule); sr != "" {
+			c.storage = compile(sr)
+			c.storageErr = c.storage == nil
+		}
+		s.byRule[r] = c
+		if r.Enabled {
+			s.compiled = append(s.compiled, c)
+		}
+	}
+	sort.SliceStable(s.compiled, func(i, j int) bool {
+		return s.compiled[i].rule.Priority > s.compiled[j].rule.Priority
+	})
+	return s
+}
+
+// Rules 返回规则集合对应的规则副本
+func (s *RuleSet) Rules() []config.AppRule {
+	return append([]config.AppRule(nil), s.rules...)
+}
+
+// ResolveFolder 根据规则解析存储文件夹和固定前缀，使用预编译的存储规则
+// 当 rule 为 nil 时返回标题作为子文件夹
+func (s *RuleSet) ResolveFolder(title string, rule *config.AppRule) (string, string) {
+	if rule == nil {
+		return utils.SanitizeFolderName(title), ""
+	}
+	c, ok := s.byRule[rule]
+	if !ok {
+		return ResolveFolder(title, rule)
+	}
+	if c.storageErr {
+		return constants.TextUnknownName, rule.FixedFolder
+	}
+	return utils.ResolveStorageFolderWith(title, c.storage, rule.FolderTemplate), rule.FixedFolder
+}
+
+// ValidatePattern 校验表达式能否被规则引擎编译
+func ValidatePattern(pattern string) error {
+	_, err := utils.CompileRegex(pattern)
+	return err
+}
+
+// RuleSetSource 返回按配置来源编译并缓存规则的函数；规则或规则组变化（含重新加载、切换配置方案）后重新编译
+// cancel 取消对配置变化的订阅，之后 rules 一直返回最后编译的规则
+func RuleSetSource(p config.Provider) (rules func() *RuleSet, cancel func()) {
+	var mu sync.Mutex
+	var cached *RuleSet
+	cancel = config.OnChange(p, config.ChangeRules, func(config.Change, config.AppConfig, config.AppConfig) {
+		mu.Lock()
+		cached = nil
+		mu.Unlock()
+	})
+	return func() *RuleSet {
+		mu.Lock()
+		defer mu.Unlock()
+		if cached == nil {
+			c := p.Snapshot()
+			cached = CompileRulesWithGroups(c.Rules, c.RuleGroups)
+		}
+		return cached
+	}, cancel
+}
+
+// GroupActive 判断规则组当前是否生效
+func (s *RuleSet) GroupActive(name string) bool {
+	return config.GroupActive(s.groups, name, time.Now())
+}
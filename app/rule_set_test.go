@@ -0,0 +1,37 @@
+package app
+
+import (
+	"testing"
+
+	"cron-shot/config"
+)
+
+// TestRuleSetSource 规则变化后重新编译，取消订阅后保留最后编译的规则
+func TestRuleSetSource(t *testing.T) {
+	store := config.NewMemoryStore(config.AppConfig{Rules: []config.AppRule{{Pattern: "a", Enabled: true}}})
+	rules, cancel := RuleSetSource(store)
+	first := rules()
+	if rules() != first {
+		t.Fatal("rule set recompiled without a config change")
+	}
+	setRules := func(pattern string) {
+		t.Helper()
+		err := store.Update(func(c *config.AppConfig) error {
+			c.Rules = []config.AppRule{{Pattern: pattern, Enabled: true}}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	setRules("b")
+	second := rules()
+	if second == first || second.Rules()[0].Pattern != "b" {
+		t.Fatalf("rule set not recompiled after a rules change: %+v", second.Rules())
+	}
+	cancel()
+	setRules("c")
+	if rules() != second {
+		t.Errorf("rule set recompiled after cancel")
+	}
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"cron-shot/config"
+	"cron-shot/sys_utils"
+	"cron-shot/utils"
+	"time"
+)
+
+// RuleTestResult 规则测试结果：按标题评估规则集合，并给出每个命中规则的存储位置
+// ExcludedBy 为终止评估的排除规则（未命中排除规则时为 nil）
+type RuleTestResult struct {
+	Title      string
+	Matches    []RuleTestMatch
+	ExcludedBy *config.AppRule
+}
+
+// RuleTestMatch 单个命中规则的测试明细
+// PatternGroups/StorageGroups: 匹配规则与存储规则的捕获组（“组名=值”）；
+// Folder/Fixed: ResolveFolder 的结果；Path: 保存时将使用的文件路径；
+// HasConditions: 规则配置了标题之外的条件（测试仅按标题评估，这些条件未参与）
+type RuleTestMatch struct {
+	Rule          *config.AppRule
+	PatternGroups []string
+	StorageGroups []string
+	Folder        string
+	Fixed         string
+	Path          string
+	HasConditions bool
+}
+
+// Test 仅按标题测试规则集合，返回命中的规则、捕获组、解析出的文件夹与最终保存路径
+// proc 与 root 用于计算路径，t 为假定的截图时间
+func (s *RuleSet) Test(title, proc, root string, t time.Time) RuleTestResult {
+	res := RuleTestResult{Title: title}
+	f := &windowFacts{info: sys_utils.WindowInfo{Title: title}}
+	matched, excluded := s.matchDetail(f, false)
+	res.ExcludedBy = excluded
+	for _, r := range matched {
+		c := s.byRule[r]
+		folder, fixed := s.ResolveFolder(title, r)
+		_, path := sys_utils.CronShotPath(root, proc, fixed, folder, t)
+		res.Matches = append(res.Matches, RuleTestMatch{
+			Rule:          r,
+			PatternGroups: utils.RegexGroups(c.title, title),
+			StorageGroups: utils.RegexGroups(c.storage, title),
+			Folder:        folder,
+			Fixed:         fixed,
+			Path:          path,
+			HasConditions: r.Match != nil,
+		})
+	}
+	return res
+}
@@ -2,44 +2,224 @@ package app
 
 import (
 	"cron-shot/config"
+	"cron-shot/sys_utils"
 	"cron-shot/utils"
-	"regexp"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/dlclark/regexp2"
 )
 
-// MatchRule 在规则列表中查找匹配窗口标题的规则
-// 优先文本等价匹配，其次正则匹配；返回匹配到的规则与是否命中
-func MatchRule(title string, rules []config.AppRule) (*config.AppRule, bool) {
-	// 一次线性扫描做等价匹配（更快且避免不必要的正则编译）
-	for i := range rules {
-		r := &rules[i]
-		if !r.Enabled {
+// Match 按标题匹配，返回第一个命中的规则与是否命中
+// 评估顺序与排除语义同 MatchAll；规则中的其它匹配条件被忽略，
+// 仅配置了其它条件（标题为空）的规则不会命中
+func (s *RuleSet) Match(title string) (*config.AppRule, bool) {
+	return first(s.match(&windowFacts{info: sys_utils.WindowInfo{Title: title}}, false))
+}
+
+// MatchWindow 返回第一个匹配窗口的规则，同时检查规则的扩展匹配条件
+// 类名、命令行、尺寸等属性仅在规则用到时才通过 b 查询
+func (s *RuleSet) MatchWindow(info sys_utils.WindowInfo, b sys_utils.CaptureBackend) (*config.AppRule, bool) {
+	return first(s.MatchAll(info, b))
+}
+
+// MatchAll 返回匹配窗口的全部规则（按评估顺序），同一窗口可据此保存到多个目标
+// 评估顺序：Priority 从高到低；同优先级内排除规则最先，其次是标题文本等价的规则，其余保持列表顺序。
+// 普通规则命中后停止评估，除非其 Continue 为真；排除规则命中后立即停止且不计入结果，
+// 在它之前已命中的（更高优先级的）Continue 规则仍然保留
+func (s *RuleSet) MatchAll(info sys_utils.WindowInfo, b sys_utils.CaptureBackend) []*config.AppRule {
+	return s.match(&windowFacts{b: b, info: info}, true)
+}
+
+// match 按评估顺序执行匹配；withConditions 为 false 时只比较标题
+func (s *RuleSet) match(f *windowFacts, withConditions bool) []*config.AppRule {
+	out, _ := s.matchDetail(f, withConditions)
+	return out
+}
+
+// matchDetail 与 match 相同，并返回终止评估的排除规则（未命中排除规则时为 nil）
+func (s *RuleSet) matchDetail(f *windowFacts, withConditions bool) ([]*config.AppRule, *config.AppRule) {
+	var out []*config.AppRule
+	for _, c := range s.ordered(f.info.Title) {
+		if !c.matches(f, withConditions) {
 			continue
 		}
-		if r.Pattern == title {
-			return r, true
+		if c.rule.Exclude {
+			return out, c.rule
+		}
+		out = append(out, c.rule)
+		if !c.rule.Continue {
+			break
 		}
 	}
-	// 兜底：逐条编译正则并尝试匹配
-	for i := range rules {
-		r := &rules[i]
-		if !r.Enabled {
-			continue
+	return out, nil
+}
+
+// ordered 返回针对给定标题的评估顺序：在已按优先级排序的基础上，
+// 同优先级内排除规则最先（不受列表位置影响），其次是标题文本等价的规则（更快且更精确）；
+// 所属组未生效的规则被跳过
+func (s *RuleSet) ordered(title string) []*compiledRule {
+	now := time.Now()
+	active := make([]*compiledRule, 0, len(s.compiled))
+	for _, c := range s.compiled {
+		if config.GroupActive(s.groups, c.rule.Group, now) {
+			active = append(active, c)
+		}
+	}
+	out := make([]*compiledRule, 0, len(active))
+	for i := 0; i < len(active); {
+		j := i
+		for j < len(active) && active[j].rule.Priority == active[i].rule.Priority {
+			j++
+		}
+		tier := active[i:j]
+		out = appendWhere(out, tier, func(r *config.AppRule) bool { return r.Exclude })
+		out = appendWhere(out, tier, func(r *config.AppRule) bool { return !r.Exclude && r.Pattern == title })
+		out = appendWhere(out, tier, func(r *config.AppRule) bool { return !r.Exclude && r.Pattern != title })
+		i = j
+	}
+	return out
+}
+
+// appendWhere 按原顺序将 rules 中满足 keep 的规则追加到 out
+func appendWhere(out, rules []*compiledRule, keep func(*config.AppRule) bool) []*compiledRule {
+	for _, c := range rules {
+		if keep(c.rule) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// first 返回列表中的第一个规则
+func first(rules []*config.AppRule) (*config.AppRule, bool) {
+	if len(rules) == 0 {
+		return nil, false
+	}
+	return rules[0], true
+}
+
+// matches 按规则的组合方式检查所有已配置条件
+func (c *compiledRule) matches(f *windowFacts, withConditions bool) bool {
+	r := c.rule
+	m := r.Match
+	var conds []func() bool
+	if m != nil {
+		conds = c.conditions(f)
+	}
+	if !withConditions {
+		if r.Pattern == "" && len(conds) > 0 {
+			return false
 		}
-		re, err := regexp.Compile(r.Pattern)
-		if err == nil && re.MatchString(title) {
-			return r, true
+		conds = nil
+	}
+	// 标题为空且配置了其它条件时，标题不参与匹配
+	if r.Pattern != "" || len(conds) == 0 {
+		title := func() bool {
+			return r.Pattern == f.info.Title || utils.RegexMatch(c.title, f.info.Title)
+		}
+		conds = append([]func() bool{title}, conds...)
+	}
+	anyOf := m != nil && withConditions && strings.EqualFold(strings.TrimSpace(m.Mode), config.MatchAny)
+	for _, cond := range conds {
+		ok := cond()
+		if anyOf && ok {
+			return true
+		}
+		if !anyOf && !ok {
+			return false
+		}
+	}
+	return !anyOf
+}
+
+// conditions 返回 WindowMatch 中已配置的条件
+func (c *compiledRule) conditions(f *windowFacts) []func() bool {
+	m := c.rule.Match
+	var conds []func() bool
+	addRegex := func(pattern string, re *regexp2.Regexp, value func() string) {
+		if pattern != "" {
+			conds = append(conds, func() bool { return utils.RegexMatch(re, value()) })
+		}
+	}
+	addRegex(m.Class, c.class, func() string { return f.details().ClassName })
+	addRegex(m.ExePath, c.exePath, func() string { return f.details().ExePath })
+	addRegex(m.CmdLine, c.cmdLine, func() string { return f.details().CmdLine })
+	addRegex(m.Parent, c.parent, func() string { return f.details().ParentProcess })
+	if m.MinWidth > 0 || m.MaxWidth > 0 || m.MinHeight > 0 || m.MaxHeight > 0 {
+		conds = append(conds, func() bool {
+			b := f.bounds()
+			return inRange(b.Dx(), m.MinWidth, m.MaxWidth) && inRange(b.Dy(), m.MinHeight, m.MaxHeight)
+		})
+	}
+	if m.Monitor > 0 {
+		conds = append(conds, func() bool { return f.monitor() == m.Monitor })
+	}
+	return conds
+}
+
+// inRange 判断 v 是否在 [min, max] 内；0 表示该侧不限
+func inRange(v, min, max int) bool {
+	return (min <= 0 || v >= min) && (max <= 0 || v <= max)
+}
+
+// windowFacts 缓存一次匹配过程中查询到的窗口属性，避免多条规则重复查询
+type windowFacts struct {
+	b    sys_utils.CaptureBackend
+	info sys_utils.WindowInfo
+	det  *sys_utils.WindowDetails
+	rect *image.Rectangle
+	mon  *int
+}
+
+// details 返回窗口类名、可执行文件路径等扩展属性
+func (f *windowFacts) details() sys_utils.WindowDetails {
+	if f.det == nil {
+		d := sys_utils.WindowDetails{}
+		if f.b != nil {
+			d = sys_utils.GetWindowDetails(f.b, f.info)
+		}
+		f.det = &d
+	}
+	return *f.det
+}
+
+// bounds 返回窗口在桌面坐标系中的矩形
+func (f *windowFacts) bounds() image.Rectangle {
+	if f.rect == nil {
+		r := image.Rectangle{}
+		if f.b != nil {
+			r = f.b.WindowBounds(f.info)
+		}
+		f.rect = &r
+	}
+	return *f.rect
+}
+
+// monitor 返回窗口中心所在显示器的序号（从 1 开始，未知时为 0）
+func (f *windowFacts) monitor() int {
+	if f.mon == nil {
+		n := 0
+		if f.b != nil {
+			if mons, err := f.b.Monitors(); err == nil {
+				r := f.bounds()
+				center := image.Pt((r.Min.X+r.Max.X)/2, (r.Min.Y+r.Max.Y)/2)
+				n = sys_utils.MonitorIndexAt(mons, center) + 1
+			}
 		}
+		f.mon = &n
 	}
-	return nil, false
+	return *f.mon
 }
 
 // ResolveFolder 根据规则解析存储文件夹和固定前缀
-// 当 rule 为 nil 时返回标题作为子文件夹；否则使用 StorageRule 解析
+// 当 rule 为 nil 时返回标题作为子文件夹；否则使用 StorageRule 与 FolderTemplate 解析
 func ResolveFolder(title string, rule *config.AppRule) (string, string) {
 	if rule == nil {
-		return title, ""
+		return utils.SanitizeFolderName(title), ""
 	}
-	// 使用正则捕获组/整体匹配解析出文件夹名，并进行安全化处理
-	folder := utils.ResolveStorageFolder(title, rule.StorageRule)
+	// 使用正则捕获组/整体匹配或文件夹模板解析出文件夹名，并进行安全化处理
+	folder := utils.ResolveStorageFolderTemplate(title, rule.StorageRule, rule.FolderTemplate)
 	return folder, rule.FixedFolder
 }
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"cron-shot/config"
+	"cron-shot/sys_utils"
+	"errors"
+	"image"
+	"reflect"
+	"testing"
+)
+
+// stubBackend 固定返回窗口类名、尺寸与显示器的截图后端
+type stubBackend struct {
+	class    string
+	bounds   image.Rectangle
+	monitors []sys_utils.Monitor
+}
+
+func (b stubBackend) ListWindows(string) ([]sys_utils.WindowInfo, error) { return nil, nil }
+func (b stubBackend) WindowCapturable(sys_utils.WindowInfo) bool         { return true }
+func (b stubBackend) WindowBounds(sys_utils.WindowInfo) image.Rectangle  { return b.bounds }
+func (b stubBackend) CaptureWindow(sys_utils.WindowInfo) (*image.RGBA, error) {
+	return nil, errors.New("not supported")
+}
+func (b stubBackend) Monitors() ([]sys_utils.Monitor, error) { return b.monitors, nil }
+func (b stubBackend) CaptureRect(image.Rectangle) (*image.RGBA, error) {
+	return nil, errors.New("not supported")
+}
+func (b stubBackend) WindowClass(sys_utils.WindowInfo) string { return b.class }
+
+func rule(pattern string, opts ...func(*config.AppRule)) config.AppRule {
+	r := config.AppRule{Pattern: pattern, Enabled: true}
+	for _, o := range opts {
+		o(&r)
+	}
+	return r
+}
+
+func priority(p int) func(*config.AppRule) { return func(r *config.AppRule) { r.Priority = p } }
+func exclude(r *config.AppRule)            { r.Exclude = true }
+func cont(r *config.AppRule)               { r.Continue = true }
+func fixed(f string) func(*config.AppRule) { return func(r *config.AppRule) { r.FixedFolder = f } }
+func match(m config.WindowMatch) func(*config.AppRule) {
+	return func(r *config.AppRule) { r.Match = &m }
+}
+
+func TestRuleSetMatchAll(t *testing.T) {
+	backend := stubBackend{
+		class:  "Chrome_WidgetWin_1",
+		bounds: image.Rect(1920, 0, 2720, 600),
+		monitors: []sys_utils.Monitor{
+			{Index: 0, Bounds: image.Rect(0, 0, 1920, 1080)},
+			{Index: 1, Bounds: image.Rect(1920, 0, 3840, 1080)},
+		},
+	}
+	tests := []struct {
+		name  string
+		title string
+		rules []config.AppRule
+		want  []string // 命中规则的 FixedFolder，按评估顺序
+	}{
+		{
+			name:  "higher priority first",
+			title: "editor",
+			rules: []config.AppRule{
+				rule(".*", fixed("low")),
+				rule("edit", priority(5), fixed("high")),
+			},
+			want: []string{"high"},
+		},
+		{
+			name:  "negative priority after default",
+			title: "editor",
+			rules: []config.AppRule{
+				rule("edit", priority(-1), fixed("neg")),
+				rule("ed", fixed("zero")),
+			},
+			want: []string{"zero"},
+		},
+		{
+			name:  "exact text first within priority",
+			title: "a.b",
+			rules: []config.AppRule{
+				rule("a", fixed("regex")),
+				rule("a.b", fixed("exact")),
+			},
+			want: []string{"exact"},
+		},
+		{
+			name:  "exact text does not jump priority",
+			title: "a.b",
+			rules: []config.AppRule{
+				rule("a.b", fixed("exact")),
+				rule("a", priority(1), fixed("regex")),
+			},
+			want: []string{"regex"},
+		},
+		{
+			name:  "list order within priority",
+			title: "editor",
+			rules: []config.AppRule{
+				rule("ed", fixed("first")),
+				rule("edit", fixed("second")),
+			},
+			want: []string{"first"},
+		},
+		{
+			name:  "exclude stops evaluation",
+			title: "设置",
+			rules: []config.AppRule{
+				rule("设置", exclude, priority(10)),
+				rule(".*", fixed("all")),
+			},
+			want: nil,
+		},
+		{
+			name:  "exclude wins regardless of list position",
+			title: "设置",
+			rules: []config.AppRule{
+				rule(".*", fixed("all")),
+				rule("设置", exclude),
+			},
+			want: nil,
+		},
+		{
+			name:  "exclude not matching",
+			title: "main",
+			rules: []config.AppRule{
+				rule(".*", fixed("all")),
+				rule("设置", exclude),
+			},
+			want: []string{"all"},
+		},
+		{
+			name:  "continue rules before exclude are kept",
+			title: "设置",
+			rules: []config.AppRule{
+				rule(".*", cont, priority(5), fixed("audit")),
+				rule("设置", exclude),
+				rule(".*", fixed("all")),
+			},
+			want: []string{"audit"},
+		},
+		{
+			name:  "continue collects several rules",
+			title: "report - excel",
+			rules: []config.AppRule{
+				rule("report", cont, fixed("reports")),
+				rule("excel", cont, fixed("excel")),
+				rule(".*", fixed("all")),
+				rule(".*", fixed("never")),
+			},
+			want: []string{"reports", "excel", "all"},
+		},
+		{
+			name:  "disabled rule skipped",
+			title: "editor",
+			rules: []config.AppRule{
+				{Pattern: "editor", Enabled: false, FixedFolder: "off"},
+				rule("edit", fixed("on")),
+			},
+			want: []string{"on"},
+		},
+		{
+			name:  "and requires all conditions",
+			title: "editor",
+			rules: []config.AppRule{
+				rule("edit", fixed("other class"), match(config.WindowMatch{Class: "^Notepad$"})),
+				rule("edit", fixed("chrome"), match(config.WindowMatch{Class: "^Chrome_", Monitor: 2})),
+			},
+			want: []string{"chrome"},
+		},
+		{
+			name:  "and fails on size",
+			title: "editor",
+			rules: []config.AppRule{
+				rule("edit", fixed("big"), match(config.WindowMatch{MinWidth: 1000})),
+			},
+			want: nil,
+		},
+		{
+			name:  "or needs one condition",
+			title: "editor",
+			rules: []config.AppRule{
+				rule("nomatch", fixed("any"), match(config.WindowMatch{Mode: config.MatchAny, Class: "^Chrome_"})),
+			},
+			want: []string{"any"},
+		},
+		{
+			name:  "or fails when nothing matches",
+			title: "editor",
+			rules: []config.AppRule{
+				rule("nomatch", fixed("any"), match(config.WindowMatch{Mode: config.MatchAny, Class: "^Notepad$", Monitor: 1})),
+			},
+			want: nil,
+		},
+		{
+			name:  "empty pattern uses conditions only",
+			title: "whatever",
+			rules: []config.AppRule{
+				rule("", fixed("size"), match(config.WindowMatch{MaxWidth: 800, MaxHeight: 600})),
+			},
+			want: []string{"size"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompileRules(tt.rules).MatchAll(sys_utils.WindowInfo{Title: tt.title}, backend)
+			var folders []string
+			for _, r := range got {
+				folders = append(folders, r.FixedFolder)
+			}
+			if !reflect.DeepEqual(folders, tt.want) {
+				t.Errorf("MatchAll(%q) = %v, want %v", tt.title, folders, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleSetMatchTitleOnly(t *testing.T) {
+	s := CompileRules([]config.AppRule{
+		rule("", fixed("conditions only"), match(config.WindowMatch{Class: ".*"})),
+		rule("edit", fixed("title"), match(config.WindowMatch{Class: "^Never$"})),
+	})
+	r, ok := s.Match("editor")
+	if !ok || r.FixedFolder != "title" {
+		t.Fatalf("Match = %v, %v; want rule \"title\" with conditions ignored", r, ok)
+	}
+}
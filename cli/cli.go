@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"cron-shot/config"
+)
+
+// usage 命令行用法说明
+const usage = `用法:
+  cron-shot [全局参数] [命令]
+
+全局参数:
+  --config-dir <目录>               配置与日志目录（优先于环境变量 CRONSHOT_HOME 与便携模式）
+  --<配置项> <值>                   仅本次运行覆盖配置项，不写入配置文件，如
+                                    --storage-root D:\shots --screenshot-interval-sec 10 --dedupe-enabled=false
+                                    配置项名为配置文件字段名（下划线换成连字符）；环境变量 CRONSHOT_<字段名>
+                                    （如 CRONSHOT_STORAGE_ROOT）同样可覆盖，优先级：默认值 < 配置文件 < 环境变量 < 命令行
+
+命令:
+  （无）                            启动图形界面
+  cron-shot group list              列出规则组及其状态
+  cron-shot group enable <组名>     启用规则组
+  cron-shot group disable <组名>    停用规则组
+  cron-shot profile list            列出配置方案（* 为当前方案）
+  cron-shot profile use <名称>      切换配置方案（运行中的界面随之切换）
+  cron-shot profile create <名称> [--from <名称>]
+                                    以当前方案（或 --from 指定的方案）为起点新建方案
+  cron-shot profile delete <名称>   删除配置方案
+  cron-shot config validate         校验配置文件，逐项列出问题
+  cron-shot config effective        列出各配置项的最终取值及其来源（default/file/env/flag）
+`
+
+// Global 全局命令行参数，位于子命令之前
+// Overrides 为按出现顺序排列的配置项覆盖（--<配置项> <值>）
+type Global struct {
+	ConfigDir string
+	Overrides []config.Override
+}
+
+// ParseGlobal 解析子命令之前的全局参数，返回全局参数与剩余参数（子命令及其参数）
+func ParseGlobal(args []string, stderr io.Writer) (Global, []string, error) {
+	var g Global
+	fs := flag.NewFlagSet("cron-shot", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	fs.StringVar(&g.ConfigDir, "config-dir", "", "配置与日志目录")
+	for _, s := range config.Settings() {
+		s := s
+		set := func(v string) error {
+			o, err := config.NewOverride(s, v, config.SourceFlag)
+			if err != nil {
+				return err
+			}
+			g.Overrides = append(g.Overrides, o)
+			return nil
+		}
+		usage := "覆盖配置项 " + s.Key
+		if s.IsBool() {
+			fs.BoolFunc(s.Flag, usage, set)
+		} else {
+			fs.Func(s.Flag, usage, set)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return g, nil, err
+	}
+	return g, fs.Args(), nil
+}
+
+// Run 执行命令行子命令并返回进程退出码
+// store 需已加载；修改后的配置由运行中的界面在重新加载配置后生效
+func Run(store *config.Store, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	// config 子命令自行报告加载问题
+	if err := store.LoadError(); err != nil && args[0] != "config" {
+		fmt.Fprintf(stderr, "警告: %v\n", err)
+	}
+	switch args[0] {
+	case "group":
+		return runGroup(store, args[1:], stdout, stderr)
+	case "profile":
+		return runProfile(store, args[1:], stdout, stderr)
+	case "config":
+		return runConfig(store, args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+	fmt.Fprintf(stderr, "未知命令: %s\n%s", args[0], usage)
+	return 2
+}
+
+// runGroup 处理 group 子命令
+func runGroup(store *config.Store, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "list":
+		printGroups(store.Snapshot(), stdout)
+		return 0
+	case "enable", "disable":
+		if len(args) != 2 {
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+		if err := store.SetRuleGroupEnabled(args[1], args[0] == "enable"); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "%s: %s\n", args[1], args[0]+"d")
+		return 0
+	}
+	fmt.Fprintf(stderr, "未知命令: group %s\n%s", args[0], usage)
+	return 2
+}
+
+// runProfile 处理 profile 子命令
+func runProfile(store *config.Store, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	var err error
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		active := store.ActiveProfile()
+		for _, name := range store.ListProfiles() {
+			mark := " "
+			if name == active {
+				mark = "*"
+			}
+			fmt.Fprintf(stdout, "%s %s\n", mark, name)
+		}
+		return 0
+	case args[0] == "use" && len(args) == 2:
+		err = store.SwitchProfile(args[1])
+		if store.ActiveProfile() == args[1] {
+			// 已切换，加载问题仅作提示
+			if err != nil {
+				fmt.Fprintf(stderr, "警告: %v\n", err)
+			}
+			err = nil
+		}
+	case args[0] == "create" && len(args) == 2:
+		err = store.CreateProfile(args[1], "")
+	case args[0] == "create" && len(args) == 4 && args[2] == "--from":
+		err = store.CreateProfile(args[1], args[3])
+	case args[0] == "delete" && len(args) == 2:
+		err = store.DeleteProfile(args[1])
+	default:
+		fmt.Fprintf(stderr, "未知命令: profile %s\n%s", strings.Join(args, " "), usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s: %s\n", args[1], args[0])
+	return 0
+}
+
+// runConfig 处理 config 子命令
+func runConfig(store *config.Store, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "validate":
+		return validateConfig(store, stdout, stderr)
+	case "effective":
+		printEffective(store, stdout)
+		return 0
+	}
+	fmt.Fprintf(stderr, "未知命令: config %s\n%s", args[0], usage)
+	return 2
+}
+
+// validateConfig 输出加载配置时发现的问题；字段错误逐行列出，有问题时返回 1
+// 校验针对文件中的原始值，而不是加载后补上默认值的结果
+func validateConfig(store *config.Store, stdout, stderr io.Writer) int {
+	err := store.LoadError()
+	if err == nil {
+		fmt.Fprintln(stdout, "OK")
+		return 0
+	}
+	var verr config.ValidationError
+	if errors.As(err, &verr) {
+		// 其余错误（如从备份恢复）与字段错误一同以 errors.Join 返回，先输出非字段错误
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				if !errors.As(e, new(config.ValidationError)) {
+					fmt.Fprintln(stderr, e)
+				}
+			}
+		}
+		fmt.Fprintln(stderr, verr.Lines())
+	} else {
+		fmt.Fprintln(stderr, err)
+	}
+	return 1
+}
+
+// printEffective 输出各配置项的最终取值与来源；来自覆盖的项附带环境变量名或参数名
+func printEffective(store *config.Store, w io.Writer) {
+	for _, e := range store.Effective() {
+		source := e.Source
+		if e.Origin != "" {
+			source += " " + e.Origin
+		}
+		fmt.Fprintf(w, "%s\t%s\t(%s)\n", e.Key, e.Value, source)
+	}
+}
+
+// printGroups 输出规则组：组名、手动启用、时间表、当前是否生效与规则数
+func printGroups(c config.AppConfig, w io.Writer) {
+	counts := map[string]int{}
+	for _, r := range c.Rules {
+		counts[r.Group]++
+	}
+	now := time.Now()
+	for _, g := range c.RuleGroups {
+		schedule := "-"
+		if s := g.Schedule; s != nil {
+			schedule = s.Start + "-" + s.End
+			if len(s.Days) > 0 {
+				days := make([]string, 0, len(s.Days))
+				for _, d := range s.Days {
+					days = append(days, time.Weekday(d).String()[:3])
+				}
+				schedule += " " + strings.Join(days, ",")
+			}
+		}
+		fmt.Fprintf(w, "%s\tenabled=%t\tactive=%t\tschedule=%s\trules=%d\n", g.Name, g.Enabled, g.Active(now), schedule, counts[g.Name])
+	}
+}
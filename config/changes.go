@@ -0,0 +1,106 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Change 配置变化的类别，可按位组合；订阅者只关心与自己相关的类别
+type Change uint
+
+const (
+	ChangeInterval       Change = 1 << iota // 截图周期
+	ChangeRules                             // 窗口规则或规则组
+	ChangeScreenRules                       // 独立屏幕规则
+	ChangeStorageRoot                       // 存储路径
+	ChangeProcess                           // 当前监控的进程
+	ChangeCaptureBackend                    // 截图后端
+	ChangeDedupe                            // 去重开关与阈值
+	ChangeBlankDetect                       // 空白帧检测
+	ChangeIdle                              // 空闲/锁屏暂停
+	ChangeStartup                           // 开机自启、自动开启截图、静默启动
+
+	// ChangeAll 所有类别
+	ChangeAll = ChangeInterval | ChangeRules | ChangeScreenRules | ChangeStorageRoot | ChangeProcess |
+		ChangeCaptureBackend | ChangeDedupe | ChangeBlankDetect | ChangeIdle | ChangeStartup
+)
+
+// changeNames 各类别在日志中的名称
+var changeNames = []struct {
+	kind Change
+	name string
+}{
+	{ChangeInterval, "interval"},
+	{ChangeRules, "rules"},
+	{ChangeScreenRules, "screen_rules"},
+	{ChangeStorageRoot, "storage_root"},
+	{ChangeProcess, "process"},
+	{ChangeCaptureBackend, "capture_backend"},
+	{ChangeDedupe, "dedupe"},
+	{ChangeBlankDetect, "blank_detect"},
+	{ChangeIdle, "idle"},
+	{ChangeStartup, "startup"},
+}
+
+// Has 判断是否包含 kinds 中的任一类别
+func (c Change) Has(kinds Change) bool { return c&kinds != 0 }
+
+// String 以 | 连接包含的类别名称，如 "interval|rules"
+func (c Change) String() string {
+	var names []string
+	for _, n := range changeNames {
+		if c.Has(n.kind) {
+			names = append(names, n.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// Changes 比较两份配置，返回发生变化的类别
+func Changes(old, cur AppConfig) Change {
+	var c Change
+	if old.ScreenshotIntervalSec != cur.ScreenshotIntervalSec {
+		c |= ChangeInterval
+	}
+	if rulesChanged(old, cur) {
+		c |= ChangeRules
+	}
+	if !reflect.DeepEqual(old.ScreenRules, cur.ScreenRules) {
+		c |= ChangeScreenRules
+	}
+	if old.StorageRoot != cur.StorageRoot {
+		c |= ChangeStorageRoot
+	}
+	if old.CurrentProcess != cur.CurrentProcess {
+		c |= ChangeProcess
+	}
+	if old.CaptureBackend != cur.CaptureBackend {
+		c |= ChangeCaptureBackend
+	}
+	if old.DedupeEnabled != cur.DedupeEnabled || old.DedupeThreshold != cur.DedupeThreshold {
+		c |= ChangeDedupe
+	}
+	if old.BlankDetectEnabled != cur.BlankDetectEnabled || old.BlankTolerance != cur.BlankTolerance || old.BlankAction != cur.BlankAction {
+		c |= ChangeBlankDetect
+	}
+	if old.IdlePauseEnabled != cur.IdlePauseEnabled || old.IdlePauseMinutes != cur.IdlePauseMinutes || old.PauseOnLockEnabled != cur.PauseOnLockEnabled {
+		c |= ChangeIdle
+	}
+	if old.AutostartEnabled != cur.AutostartEnabled || old.AutoCaptureEnabled != cur.AutoCaptureEnabled || old.SilentStartEnabled != cur.SilentStartEnabled {
+		c |= ChangeStartup
+	}
+	return c
+}
+
+// OnChange 订阅 kinds 中任一类别的配置变化；fn 收到本次变化的全部类别与前后配置
+// 与 Subscribe 相同，fn 在引起变化的协程中调用（界面需自行切回界面线程），返回取消订阅的函数
+func OnChange(p Provider, kinds Change, fn func(changed Change, old, cur AppConfig)) (cancel func()) {
+	return p.Subscribe(func(old, cur AppConfig) {
+		if changed := Changes(old, cur); changed.Has(kinds) {
+			fn(changed, old, cur)
+		}
+	})
+}
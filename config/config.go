@@ -2,123 +2,191 @@ package config
 
 import (
 	"cron-shot/constants"
-	"encoding/json"
-	"os"
 	"path/filepath"
 	"sync"
 
 	"cron-shot/sys_utils"
 )
 
+// 截图目标类型
+const (
+	TargetWindow      = "window"       // 命中的窗口本身（默认）
+	TargetMonitor     = "monitor"      // 单个显示器
+	TargetAllMonitors = "all_monitors" // 所有显示器拼接
+	TargetRect        = "rect"         // 固定桌面区域
+)
+
+// CaptureRect 表示虚拟桌面坐标系中的固定截图区域
+type CaptureRect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// 空白帧处理方式
+const (
+	BlankActionSkip  = "skip"  // 跳过保存并记录日志
+	BlankActionRetry = "retry" // 换用其它截图方式重试
+)
+
+// 匹配条件组合方式
+const (
+	MatchAll = "and" // 所有已配置条件均满足（默认）
+	MatchAny = "or"  // 任一已配置条件满足
+)
+
+// WindowMatch 表示标题之外的窗口匹配条件，留空的条件不参与匹配
+// Mode: and/or，决定与标题 Pattern 及彼此之间的组合方式；
+// Class/ExePath/CmdLine/Parent: 窗口类名、可执行文件完整路径、命令行、父进程名（均为正则）；
+// MinWidth/MaxWidth/MinHeight/MaxHeight: 窗口尺寸范围（像素，0 表示不限）；
+// Monitor: 窗口所在显示器序号（从 1 开始，0 表示不限）
+type WindowMatch struct {
+	Mode      string `json:"mode,omitempty"`
+	Class     string `json:"class,omitempty"`
+	ExePath   string `json:"exe_path,omitempty"`
+	CmdLine   string `json:"cmdline,omitempty"`
+	Parent    string `json:"parent,omitempty"`
+	MinWidth  int    `json:"min_width,omitempty"`
+	MaxWidth  int    `json:"max_width,omitempty"`
+	MinHeight int    `json:"min_height,omitempty"`
+	MaxHeight int    `json:"max_height,omitempty"`
+	Monitor   int    `json:"monitor,omitempty"`
+}
+
 // AppRule 表示窗口规则配置
 // Pattern: 窗口匹配文本或正则；Enabled: 是否激活；
-// StorageRule: 存储文件夹解析规则（支持正则捕获组）；
-// FixedFolder: 固定文件夹前缀（不为空时，截图存储于该文件夹下）
+// StorageRule: 存储文件夹解析规则（支持正则捕获组与命名组）；
+// FolderTemplate: 文件夹模板（如 {project}/{branch|main}，为空时取第一个捕获组或整体匹配）；
+// FixedFolder: 固定文件夹前缀（不为空时，截图存储于该文件夹下）；
+// Target: 命中后截取的目标（为空等同 window）；Monitor: 显示器序号（从 1 开始，0 表示窗口所在显示器）；
+// Rect: Target 为 rect 时的截图区域；Match: 标题之外的匹配条件（可为空）；
+// Exclude: 排除规则，命中后窗口不再参与后续规则（先于同优先级的普通规则评估）；Priority: 优先级（越大越先匹配，相同时按列表顺序）；
+// Continue: 命中后继续匹配后续规则，使同一窗口保存到多个目标；
+// Group: 所属规则组（为空表示不属于任何组，仅受 Enabled 控制）
 type AppRule struct {
-	Pattern     string `json:"pattern"`
-	Enabled     bool   `json:"enabled"`
-	StorageRule string `json:"storage_rule"`
-	FixedFolder string `json:"fixed_folder"`
+	Pattern        string       `json:"pattern"`
+	Enabled        bool         `json:"enabled"`
+	StorageRule    string       `json:"storage_rule"`
+	FixedFolder    string       `json:"fixed_folder"`
+	FolderTemplate string       `json:"folder_template,omitempty"`
+	Target         string       `json:"target,omitempty"`
+	Monitor        int          `json:"monitor,omitempty"`
+	Rect           *CaptureRect `json:"rect,omitempty"`
+	Match          *WindowMatch `json:"match,omitempty"`
+	Exclude        bool         `json:"exclude,omitempty"`
+	Priority       int          `json:"priority,omitempty"`
+	Continue       bool         `json:"continue,omitempty"`
+	Group          string       `json:"group,omitempty"`
+}
+
+// ScreenRule 表示独立于进程窗口的屏幕截图规则
+// Name: 规则名（同时作为存储文件夹名）；Target: monitor/all_monitors/rect；
+// Monitor: 显示器序号（从 1 开始，0 表示主显示器）；Rect: 固定区域
+type ScreenRule struct {
+	Name    string       `json:"name"`
+	Enabled bool         `json:"enabled"`
+	Target  string       `json:"target"`
+	Monitor int          `json:"monitor,omitempty"`
+	Rect    *CaptureRect `json:"rect,omitempty"`
 }
 
 // AppConfig 应用整体配置
-// StorageRoot: 截图根目录；ScreenshotIntervalSec: 自动截图周期（秒）；
+// SchemaVersion: 配置文件结构版本（旧版本在加载时依次迁移）；StorageRoot: 截图根目录；ScreenshotIntervalSec: 自动截图周期（秒）；
 // DedupeEnabled: 去重开关；CurrentProcess: 当前监控进程；
 // AutostartEnabled: 开机自启；AutoCaptureEnabled: 启动后自动开启截图；
-// SilentStartEnabled: 静默启动到托盘；Rules: 规则列表
+// SilentStartEnabled: 静默启动到托盘；Rules: 规则列表；ScreenRules: 屏幕规则列表；
+// RuleGroups: 规则组（可整体启用并按时间表自动启用）；
+// IdlePauseEnabled: 空闲/锁屏时暂停截图；IdlePauseMinutes: 空闲判定时长（分钟）；
+// PauseOnLockEnabled: 锁屏或屏保运行时暂停；
+// BlankDetectEnabled: 空白帧检测；BlankTolerance: 空白判定容差（0-64）；BlankAction: skip/retry；
+// CaptureBackend: 截图后端（win32/win32_dwm/x11，为空时自动选择）
 type AppConfig struct {
-	StorageRoot           string    `json:"storage_root"`
-	ScreenshotIntervalSec int       `json:"screenshot_interval_sec"`
-	DedupeEnabled         bool      `json:"dedupe_enabled"`
-	DedupeThreshold       int       `json:"dedupe_threshold"`
-	CurrentProcess        string    `json:"current_process"`
-	AutostartEnabled      bool      `json:"autostart_enabled"`
-	AutoCaptureEnabled    bool      `json:"auto_capture_enabled"`
-	SilentStartEnabled    bool      `json:"silent_start_enabled"`
-	Rules                 []AppRule `json:"rules"`
+	SchemaVersion         int          `json:"schema_version"`
+	StorageRoot           string       `json:"storage_root"`
+	ScreenshotIntervalSec int          `json:"screenshot_interval_sec"`
+	DedupeEnabled         bool         `json:"dedupe_enabled"`
+	DedupeThreshold       int          `json:"dedupe_threshold"`
+	CurrentProcess        string       `json:"current_process"`
+	AutostartEnabled      bool         `json:"autostart_enabled"`
+	AutoCaptureEnabled    bool         `json:"auto_capture_enabled"`
+	SilentStartEnabled    bool         `json:"silent_start_enabled"`
+	Rules                 []AppRule    `json:"rules"`
+	IdlePauseEnabled      bool         `json:"idle_pause_enabled"`
+	IdlePauseMinutes      int          `json:"idle_pause_minutes"`
+	PauseOnLockEnabled    bool         `json:"pause_on_lock_enabled"`
+	ScreenRules           []ScreenRule `json:"screen_rules"`
+	RuleGroups            []RuleGroup  `json:"rule_groups,omitempty"`
+	BlankDetectEnabled    bool         `json:"blank_detect_enabled"`
+	BlankTolerance        int          `json:"blank_tolerance"`
+	BlankAction           string       `json:"blank_action"`
+	CaptureBackend        string       `json:"capture_backend"`
+}
+
+// defaultConfig 返回默认配置；配置文件中缺失的字段保持默认值
+func defaultConfig() AppConfig {
+	return AppConfig{
+		SchemaVersion:         CurrentSchemaVersion,
+		StorageRoot:           GetDefaultStorageRoot(),
+		ScreenshotIntervalSec: 5,
+		DedupeThreshold:       100,
+		IdlePauseMinutes:      10,
+		BlankTolerance:        4,
+		BlankAction:           BlankActionSkip,
+	}
 }
 
 var (
-	mu  sync.RWMutex
-	app AppConfig
+	defaultMu    sync.Mutex
+	defaultStore *Store
 )
 
-// Init 初始化默认配置并尝试加载持久化文件
-func Init() {
-	app.StorageRoot = GetDefaultStorageRoot()
-	app.ScreenshotIntervalSec = 5
-	app.DedupeEnabled = false
-	app.DedupeThreshold = 100
-	_ = Load()
+// Init 创建基于配置目录（BaseDir）的默认存储，并加载上次使用的配置方案；overrides 为环境变量与命令行参数的覆盖
+// 以下包级函数是默认存储的兼容封装；新代码应通过构造函数接收 Provider 或 *Store
+func Init(overrides ...Override) {
+	s := NewStore(BaseDir())
+	s.SetOverrides(overrides)
+	defaultMu.Lock()
+	defaultStore = s
+	defaultMu.Unlock()
+	_ = s.Load()
 }
 
-// configPath 返回配置文件路径：%APPDATA%/CronShot/config.json
-func configPath() string {
-	dir, _ := os.UserConfigDir()
-	if dir == "" {
-		dir = "."
+// Default 返回默认存储；Init 之前调用时返回只含默认值的内存存储
+func Default() *Store {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultStore == nil {
+		defaultStore = NewMemoryStore(defaultConfig())
 	}
-	p := filepath.Join(dir, "CronShot")
-	_ = os.MkdirAll(p, 0755)
-	return filepath.Join(p, "config.json")
+	return defaultStore
 }
 
-// Load 读取配置文件（JSON），并填充到全局 app 变量
-func Load() error {
-	mu.Lock()
-	defer mu.Unlock()
-	p := configPath()
-	f, err := os.Open(p)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
-	}
-	defer f.Close()
-	var c AppConfig
-	dec := json.NewDecoder(f)
-	if err := dec.Decode(&c); err != nil {
-		return err
-	}
-	if c.StorageRoot != "" {
-		app.StorageRoot = c.StorageRoot
-	}
-	if c.ScreenshotIntervalSec > 0 {
-		app.ScreenshotIntervalSec = c.ScreenshotIntervalSec
-	}
-	app.DedupeEnabled = c.DedupeEnabled
-	if c.DedupeThreshold > 0 {
-		app.DedupeThreshold = c.DedupeThreshold
-	}
-	app.CurrentProcess = c.CurrentProcess
-	app.AutostartEnabled = c.AutostartEnabled
-	app.AutoCaptureEnabled = c.AutoCaptureEnabled
-	app.SilentStartEnabled = c.SilentStartEnabled
-	app.Rules = c.Rules
-	return nil
+// set 通过默认存储的事务修改配置项，随后延迟保存
+func set(f func(c *AppConfig)) {
+	_ = Default().Update(func(c *AppConfig) error { f(c); return nil })
 }
 
-// Save 写入当前 app 配置到文件（JSON 缩进）
-func Save() error {
-	mu.RLock()
-	c := app
-	mu.RUnlock()
-	f, err := os.Create(configPath())
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "  ")
-	return enc.Encode(c)
-}
+// Load 重新读取默认存储的配置文件
+func Load() error { return Default().Load() }
+
+// Save 立即写入默认存储的配置
+func Save() error { return Default().Save() }
+
+// Flush 写入默认存储尚未保存的修改
+func Flush() error { return Default().Flush() }
+
+// Snapshot 返回默认存储当前配置的副本
+func Snapshot() AppConfig { return Default().Snapshot() }
+
+// LoadError 返回默认存储最近一次加载配置时的错误
+func LoadError() error { return Default().LoadError() }
 
 // GetStorageRoot 返回截图根目录
-func GetStorageRoot() string { mu.RLock(); defer mu.RUnlock(); return app.StorageRoot }
+func GetStorageRoot() string { return Snapshot().StorageRoot }
 
 // SetStorageRoot 设置截图根目录并持久化
-func SetStorageRoot(p string) { mu.Lock(); app.StorageRoot = p; mu.Unlock(); _ = Save() }
+func SetStorageRoot(p string) { set(func(c *AppConfig) { c.StorageRoot = p }) }
 
 // GetDefaultStorageRoot 返回默认截图根目录（系统图片目录/CronShot）
 func GetDefaultStorageRoot() string {
@@ -126,60 +194,119 @@ func GetDefaultStorageRoot() string {
 }
 
 // GetScreenshotIntervalSec 返回自动截图周期（秒）
-func GetScreenshotIntervalSec() int { mu.RLock(); defer mu.RUnlock(); return app.ScreenshotIntervalSec }
+func GetScreenshotIntervalSec() int { return Snapshot().ScreenshotIntervalSec }
 
 // SetScreenshotIntervalSec 设置自动截图周期（秒）并持久化
-func SetScreenshotIntervalSec(n int) {
-	mu.Lock()
-	app.ScreenshotIntervalSec = n
-	mu.Unlock()
-	_ = Save()
-}
+func SetScreenshotIntervalSec(n int) { set(func(c *AppConfig) { c.ScreenshotIntervalSec = n }) }
 
 // GetDedupeEnabled 返回是否启用去重
-func GetDedupeEnabled() bool { mu.RLock(); defer mu.RUnlock(); return app.DedupeEnabled }
+func GetDedupeEnabled() bool { return Snapshot().DedupeEnabled }
 
 // SetDedupeEnabled 设置是否启用去重并持久化
-func SetDedupeEnabled(v bool) { mu.Lock(); app.DedupeEnabled = v; mu.Unlock(); _ = Save() }
+func SetDedupeEnabled(v bool) { set(func(c *AppConfig) { c.DedupeEnabled = v }) }
 
-func GetDedupeThreshold() int  { mu.RLock(); defer mu.RUnlock(); return app.DedupeThreshold }
-func SetDedupeThreshold(n int) { mu.Lock(); app.DedupeThreshold = n; mu.Unlock(); _ = Save() }
+func GetDedupeThreshold() int  { return Snapshot().DedupeThreshold }
+func SetDedupeThreshold(n int) { set(func(c *AppConfig) { c.DedupeThreshold = n }) }
 
 // GetCurrentProcess 返回当前监控进程名
-func GetCurrentProcess() string { mu.RLock(); defer mu.RUnlock(); return app.CurrentProcess }
+func GetCurrentProcess() string { return Snapshot().CurrentProcess }
 
 // SetCurrentProcess 设置当前监控进程名并持久化
-func SetCurrentProcess(p string) { mu.Lock(); app.CurrentProcess = p; mu.Unlock(); _ = Save() }
+func SetCurrentProcess(p string) { set(func(c *AppConfig) { c.CurrentProcess = p }) }
 
 // GetRules 返回规则切片副本
-func GetRules() []AppRule {
-	mu.RLock()
-	defer mu.RUnlock()
-	return append([]AppRule(nil), app.Rules...)
-}
+func GetRules() []AppRule { return Snapshot().Rules }
 
 // SetRules 设置规则列表并持久化
-func SetRules(r []AppRule) {
-	mu.Lock()
-	app.Rules = append([]AppRule(nil), r...)
-	mu.Unlock()
-	_ = Save()
+func SetRules(r []AppRule) { set(func(c *AppConfig) { c.Rules = append([]AppRule(nil), r...) }) }
+
+// GetScreenRules 返回屏幕规则切片副本
+func GetScreenRules() []ScreenRule { return Snapshot().ScreenRules }
+
+// SetScreenRules 设置屏幕规则列表并持久化
+func SetScreenRules(r []ScreenRule) {
+	set(func(c *AppConfig) { c.ScreenRules = append([]ScreenRule(nil), r...) })
 }
 
 // GetAutostartEnabled 返回是否开机自启
-func GetAutostartEnabled() bool { mu.RLock(); defer mu.RUnlock(); return app.AutostartEnabled }
+func GetAutostartEnabled() bool { return Snapshot().AutostartEnabled }
 
 // SetAutostartEnabled 设置开机自启并持久化
-func SetAutostartEnabled(v bool) { mu.Lock(); app.AutostartEnabled = v; mu.Unlock(); _ = Save() }
+func SetAutostartEnabled(v bool) { set(func(c *AppConfig) { c.AutostartEnabled = v }) }
 
 // GetAutoCaptureEnabled 返回是否自动开启截图
-func GetAutoCaptureEnabled() bool { mu.RLock(); defer mu.RUnlock(); return app.AutoCaptureEnabled }
+func GetAutoCaptureEnabled() bool { return Snapshot().AutoCaptureEnabled }
 
 // SetAutoCaptureEnabled 设置是否自动开启截图并持久化
-func SetAutoCaptureEnabled(v bool) { mu.Lock(); app.AutoCaptureEnabled = v; mu.Unlock(); _ = Save() }
+func SetAutoCaptureEnabled(v bool) { set(func(c *AppConfig) { c.AutoCaptureEnabled = v }) }
 
 // GetSilentStartEnabled 返回是否启用静默启动
-func GetSilentStartEnabled() bool { mu.RLock(); defer mu.RUnlock(); return app.SilentStartEnabled }
+func GetSilentStartEnabled() bool { return Snapshot().SilentStartEnabled }
 
 // SetSilentStartEnabled 设置是否启用静默启动并持久化
-func SetSilentStartEnabled(v bool) { mu.Lock(); app.SilentStartEnabled = v; mu.Unlock(); _ = Save() }
+func SetSilentStartEnabled(v bool) { set(func(c *AppConfig) { c.SilentStartEnabled = v }) }
+
+// GetIdlePauseEnabled 返回是否在空闲/锁屏时暂停截图
+func GetIdlePauseEnabled() bool { return Snapshot().IdlePauseEnabled }
+
+// SetIdlePauseEnabled 设置是否在空闲/锁屏时暂停截图并持久化
+func SetIdlePauseEnabled(v bool) { set(func(c *AppConfig) { c.IdlePauseEnabled = v }) }
+
+// GetIdlePauseMinutes 返回空闲判定时长（分钟）
+func GetIdlePauseMinutes() int { return Snapshot().IdlePauseMinutes }
+
+// SetIdlePauseMinutes 设置空闲判定时长（分钟）并持久化
+func SetIdlePauseMinutes(n int) { set(func(c *AppConfig) { c.IdlePauseMinutes = n }) }
+
+// GetPauseOnLockEnabled 返回锁屏或屏保时是否暂停
+func GetPauseOnLockEnabled() bool { return Snapshot().PauseOnLockEnabled }
+
+// SetPauseOnLockEnabled 设置锁屏或屏保时是否暂停并持久化
+func SetPauseOnLockEnabled(v bool) { set(func(c *AppConfig) { c.PauseOnLockEnabled = v }) }
+
+// GetBlankDetectEnabled 返回是否启用空白帧检测
+func GetBlankDetectEnabled() bool { return Snapshot().BlankDetectEnabled }
+
+// SetBlankDetectEnabled 设置是否启用空白帧检测并持久化
+func SetBlankDetectEnabled(v bool) { set(func(c *AppConfig) { c.BlankDetectEnabled = v }) }
+
+// GetBlankTolerance 返回空白帧判定容差
+func GetBlankTolerance() int { return Snapshot().BlankTolerance }
+
+// SetBlankTolerance 设置空白帧判定容差并持久化
+func SetBlankTolerance(n int) { set(func(c *AppConfig) { c.BlankTolerance = n }) }
+
+// GetBlankAction 返回空白帧处理方式（skip/retry）
+func GetBlankAction() string { return Snapshot().BlankAction }
+
+// SetBlankAction 设置空白帧处理方式并持久化
+func SetBlankAction(v string) { set(func(c *AppConfig) { c.BlankAction = v }) }
+
+// GetCaptureBackend 返回配置的截图后端名称（为空表示自动选择）
+func GetCaptureBackend() string { return Snapshot().CaptureBackend }
+
+// SetCaptureBackend 设置截图后端名称并持久化
+func SetCaptureBackend(v string) { set(func(c *AppConfig) { c.CaptureBackend = v }) }
+
+// SetSaveErrorHandler 设置默认存储延迟保存失败时的回调
+func SetSaveErrorHandler(f func(error)) { Default().SetSaveErrorHandler(f) }
+
+// Watch 监听默认存储的配置文件
+func Watch(onReload func(old, cur AppConfig, err error)) (stop func(), err error) {
+	return Default().Watch(onReload)
+}
+
+// ActiveProfile 返回默认存储的当前配置方案
+func ActiveProfile() string { return Default().ActiveProfile() }
+
+// ListProfiles 返回默认存储的所有配置方案
+func ListProfiles() []string { return Default().ListProfiles() }
+
+// CreateProfile 在默认存储中新建配置方案
+func CreateProfile(name, from string) error { return Default().CreateProfile(name, from) }
+
+// DeleteProfile 删除默认存储中的配置方案
+func DeleteProfile(name string) error { return Default().DeleteProfile(name) }
+
+// SwitchProfile 切换默认存储的配置方案
+func SwitchProfile(name string) error { return Default().SwitchProfile(name) }
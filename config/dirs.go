@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// HomeEnv 指定配置目录的环境变量
+const HomeEnv = "CRONSHOT_HOME"
+
+// PortableMarker 便携模式标记文件：可执行文件所在目录存在该文件时，配置与日志保存在该目录
+const PortableMarker = "portable.txt"
+
+// 配置目录的来源
+const (
+	DirSourceFlag     = "flag"     // 命令行 --config-dir
+	DirSourceEnv      = "env"      // 环境变量 CRONSHOT_HOME
+	DirSourcePortable = "portable" // 便携模式
+	DirSourceDefault  = "default"  // 系统配置目录/CronShot
+)
+
+var (
+	dirMu       sync.Mutex
+	dirOverride string // SetBaseDir 指定的目录
+	resolvedDir string // 解析后的目录，首次使用时确定
+	dirSource   string
+)
+
+// SetBaseDir 指定配置目录（--config-dir），需在 Init 之前调用
+func SetBaseDir(dir string) {
+	dirMu.Lock()
+	defer dirMu.Unlock()
+	dirOverride = dir
+	resolvedDir = ""
+}
+
+// BaseDir 返回配置目录（配置文件、配置方案与日志所在目录），按以下顺序确定：
+// --config-dir、环境变量 CRONSHOT_HOME、便携模式（可执行文件旁存在 portable.txt）、%APPDATA%/CronShot
+func BaseDir() string {
+	dirMu.Lock()
+	defer dirMu.Unlock()
+	if resolvedDir == "" {
+		resolvedDir, dirSource = resolveBaseDir()
+		if abs, err := filepath.Abs(resolvedDir); err == nil {
+			resolvedDir = abs
+		}
+		_ = os.MkdirAll(resolvedDir, 0755)
+	}
+	return resolvedDir
+}
+
+// BaseDirSource 返回配置目录的来源（flag/env/portable/default）
+func BaseDirSource() string {
+	BaseDir()
+	dirMu.Lock()
+	defer dirMu.Unlock()
+	return dirSource
+}
+
+// resolveBaseDir 按优先级解析配置目录及其来源
+func resolveBaseDir() (string, string) {
+	if dirOverride != "" {
+		return dirOverride, DirSourceFlag
+	}
+	if v := strings.TrimSpace(os.Getenv(HomeEnv)); v != "" {
+		return v, DirSourceEnv
+	}
+	if exe, err := os.Executable(); err == nil {
+		dir := filepath.Dir(exe)
+		if _, err := os.Stat(filepath.Join(dir, PortableMarker)); err == nil {
+			return dir, DirSourcePortable
+		}
+	}
+	dir, _ := os.UserConfigDir()
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Join(dir, "CronShot"), DirSourceDefault
+}
@@ -0,0 +1,338 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configExts 配置文件扩展名，按优先级排列：同一配置方案存在多种格式的文件时使用靠前的
+// 程序默认写入 JSON；用户将文件改为 YAML 或 TOML 后以其为准
+var configExts = []string{".yaml", ".yml", ".toml", ".json"}
+
+// isTOMLPath 判断路径是否为 TOML 文件
+func isTOMLPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".toml")
+}
+
+// decodeConfigFile 按扩展名将配置文件内容转换为 JSON 对象，供迁移与按 JSON 字段名解码
+func decodeConfigFile(path string, data []byte) ([]byte, error) {
+	var m map[string]interface{}
+	switch {
+	case isYAMLPath(path):
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+	case isTOMLPath(path):
+		if err := toml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+	default:
+		return data, nil
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%s: not a config object", filepath.Base(path))
+	}
+	return json.Marshal(m)
+}
+
+// validConfigData 判断内容是否为可解析的配置对象（格式由扩展名决定）
+func validConfigData(path string, data []byte) bool {
+	j, err := decodeConfigFile(path, data)
+	return err == nil && validConfigJSON(j)
+}
+
+// validConfigJSON 判断内容是否为可解析的配置 JSON 对象
+func validConfigJSON(data []byte) bool {
+	var m map[string]json.RawMessage
+	return json.Unmarshal(data, &m) == nil && m != nil
+}
+
+// encodeConfigFile 按扩展名序列化配置；prev 为文件当前内容，YAML 与 TOML 据此保留用户的注释
+func encodeConfigFile(path string, c AppConfig, prev []byte) ([]byte, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case isYAMLPath(path):
+		return encodeYAMLConfig(data, prev)
+	case isTOMLPath(path):
+		return encodeTOMLConfig(data, prev)
+	}
+	return append(data, '\n'), nil
+}
+
+// encodeYAMLConfig 将 JSON 配置转换为 YAML，并从 prev 中按字段路径沿用注释与取值样式（如引号）
+func encodeYAMLConfig(data, prev []byte) ([]byte, error) {
+	node, err := jsonToYAMLNode(data)
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}
+	var old yaml.Node
+	if len(prev) > 0 && yaml.Unmarshal(prev, &old) == nil && len(old.Content) == 1 {
+		doc.HeadComment, doc.FootComment = old.HeadComment, old.FootComment
+		copyYAMLComments(old.Content[0], node)
+	}
+	return encodeYAMLNode(doc)
+}
+
+// copyYAMLComments 将 old 节点树上的注释复制到 cur 中对应的节点
+// 映射按键名对应；序列中的映射按 pattern/name 取值对应（规则增删、移动后注释跟随规则），其余按位置对应
+func copyYAMLComments(old, cur *yaml.Node) {
+	cur.HeadComment, cur.LineComment, cur.FootComment = old.HeadComment, old.LineComment, old.FootComment
+	if old.Kind != cur.Kind {
+		return
+	}
+	cur.Style = old.Style
+	switch cur.Kind {
+	case yaml.MappingNode:
+		keys := map[string]int{}
+		for i := 0; i+1 < len(old.Content); i += 2 {
+			keys[old.Content[i].Value] = i
+		}
+		for i := 0; i+1 < len(cur.Content); i += 2 {
+			j, ok := keys[cur.Content[i].Value]
+			if !ok {
+				continue
+			}
+			k := old.Content[j]
+			cur.Content[i].HeadComment, cur.Content[i].LineComment, cur.Content[i].FootComment = k.HeadComment, k.LineComment, k.FootComment
+			copyYAMLComments(old.Content[j+1], cur.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		used := make([]bool, len(old.Content))
+		for i, item := range cur.Content {
+			j := -1
+			if id := yamlItemID(item); id != "" {
+				for k, o := range old.Content {
+					if !used[k] && yamlItemID(o) == id {
+						j = k
+						break
+					}
+				}
+			} else if i < len(old.Content) && !used[i] && yamlItemID(old.Content[i]) == "" {
+				j = i
+			}
+			if j >= 0 {
+				used[j] = true
+				copyYAMLComments(old.Content[j], item)
+			}
+		}
+	}
+}
+
+// yamlItemID 返回序列项的标识：映射中 pattern 或 name 的取值，没有时为空
+func yamlItemID(n *yaml.Node) string {
+	if n.Kind != yaml.MappingNode {
+		return ""
+	}
+	for _, key := range []string{"pattern", "name"} {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key && n.Content[i+1].Kind == yaml.ScalarNode {
+				return key + "=" + n.Content[i+1].Value
+			}
+		}
+	}
+	return ""
+}
+
+// encodeTOMLConfig 将 JSON 配置转换为 TOML，并从 prev 中沿用注释
+// TOML 没有 null，取值为 null 的字段（如空的规则列表）省略，加载时保持默认值
+func encodeTOMLConfig(data, prev []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(tomlValue(v)); err != nil {
+		return nil, err
+	}
+	if len(prev) == 0 {
+		return buf.Bytes(), nil
+	}
+	return restoreTOMLComments(prev, buf.Bytes()), nil
+}
+
+// tomlValue 将 JSON 解码结果转换为 TOML 可编码的值：整数保持整数，null 被省略
+func tomlValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			if e != nil {
+				out[k] = tomlValue(e)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0, len(x))
+		for _, e := range x {
+			if e != nil {
+				out = append(out, tomlValue(e))
+			}
+		}
+		return out
+	case json.Number:
+		if n, err := x.Int64(); err == nil {
+			return n
+		}
+		f, _ := x.Float64()
+		return f
+	}
+	return v
+}
+
+// restoreTOMLComments 将 prev 中的注释按锚点（表路径与键名）放回 cur：
+// 文件开头的注释块保留在开头，键或表头上方的注释行与行尾注释随对应的键或表头
+func restoreTOMLComments(prev, cur []byte) []byte {
+	oldLines := strings.Split(strings.TrimRight(string(prev), "\n"), "\n")
+	newLines := strings.Split(strings.TrimRight(string(cur), "\n"), "\n")
+	oldAnchors := tomlAnchors(oldLines)
+
+	// 文件开头的注释块（直到第一行非注释内容）
+	var header []string
+	start := 0
+	for start < len(oldLines) && isTOMLComment(oldLines[start]) {
+		header = append(header, oldLines[start])
+		start++
+	}
+	above := map[string][]string{}
+	inline := map[string]string{}
+	var block []string
+	for i := start; i < len(oldLines); i++ {
+		line := oldLines[i]
+		if isTOMLComment(line) {
+			block = append(block, strings.TrimSpace(line))
+			continue
+		}
+		if a := oldAnchors[i]; a != "" {
+			if len(block) > 0 {
+				above[a] = block
+			}
+			if _, c := splitTOMLComment(line); c != "" {
+				inline[a] = c
+			}
+		}
+		block = nil
+	}
+
+	var out []string
+	if len(header) > 0 {
+		out = append(out, header...)
+		if len(newLines) > 0 && strings.TrimSpace(newLines[0]) != "" {
+			out = append(out, "")
+		}
+	}
+	for i, anchor := range tomlAnchors(newLines) {
+		text := newLines[i]
+		if c, ok := above[anchor]; ok {
+			out = append(out, c...)
+		}
+		if c, ok := inline[anchor]; ok {
+			text += " " + c
+		}
+		out = append(out, text)
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// isTOMLComment 判断是否为注释行
+func isTOMLComment(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+// splitTOMLComment 拆分行内容与行尾注释（引号内的 # 不视为注释）
+func splitTOMLComment(line string) (code, comment string) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case quote != 0:
+			if ch == '\\' && quote == '"' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#':
+			return strings.TrimRight(line[:i], " \t"), line[i:]
+		}
+	}
+	return line, ""
+}
+
+// tomlAnchors 为每一行计算锚点：表头行为 "[表路径]"，键值行为 "表路径.键名"，其余行为空
+// 数组表（[[rules]]）的各项以其中 pattern 或 name 的取值区分，没有时以序号区分
+func tomlAnchors(lines []string) []string {
+	anchors := make([]string, len(lines))
+	// 数组表各项的标识：当前项之后、下一个表头之前的 pattern/name 键
+	itemID := func(from int) string {
+		for _, key := range []string{"pattern", "name"} {
+			for i := from; i < len(lines); i++ {
+				code, _ := splitTOMLComment(strings.TrimSpace(lines[i]))
+				if strings.HasPrefix(code, "[") {
+					break
+				}
+				if k, v, ok := strings.Cut(code, "="); ok && strings.TrimSpace(k) == key {
+					// 按取值比较，'a' 与 "a" 视为相同
+					var m map[string]interface{}
+					if toml.Unmarshal([]byte("v = "+v), &m) == nil {
+						return key + "=" + fmt.Sprint(m["v"])
+					}
+					return key + "=" + strings.TrimSpace(v)
+				}
+			}
+		}
+		return ""
+	}
+	items := map[string]string{} // 数组表路径 -> 当前项的锚点路径
+	counts := map[string]int{}
+	table := ""
+	for i, line := range lines {
+		code, _ := splitTOMLComment(strings.TrimSpace(line))
+		switch {
+		case code == "" || strings.HasPrefix(code, "#"):
+			continue
+		case strings.HasPrefix(code, "[[") && strings.HasSuffix(code, "]]"):
+			path := resolveTOMLPath(strings.TrimSpace(code[2:len(code)-2]), items)
+			id := itemID(i + 1)
+			if id == "" {
+				id = fmt.Sprintf("#%d", counts[path])
+			}
+			counts[path]++
+			table = path + "[" + id + "]"
+			items[path] = table
+			anchors[i] = "[" + table + "]"
+		case strings.HasPrefix(code, "["):
+			table = resolveTOMLPath(strings.Trim(code, "[] "), items)
+			anchors[i] = "[" + table + "]"
+		default:
+			if k, _, ok := strings.Cut(code, "="); ok {
+				anchors[i] = table + "." + strings.TrimSpace(k)
+			}
+		}
+	}
+	return anchors
+}
+
+// resolveTOMLPath 将表路径中的数组表前缀替换为其当前项，如 rules.match -> rules[pattern="a"].match
+func resolveTOMLPath(path string, items map[string]string) string {
+	parts := strings.Split(path, ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		if item, ok := items[strings.Join(parts[:i], ".")]; ok {
+			return item + "." + strings.Join(parts[i:], ".")
+		}
+	}
+	return path
+}
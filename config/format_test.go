@@ -0,0 +1,242 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const yamlCommented = `# CronShot 配置
+# 由部署脚本生成
+
+storage_root: 'D:\Shots' # 截图根目录
+screenshot_interval_sec: 10
+rules:
+  # 编辑器
+  - pattern: "(.*) - Visual Studio Code"
+    enabled: true # 默认启用
+    storage_rule: "(.*) - Visual Studio Code"
+    fixed_folder: ""
+  # 浏览器
+  - pattern: "Chrome #1"
+    enabled: true
+    storage_rule: ""
+    fixed_folder: "web" # 含 # 的标题
+  # 临时规则
+  - pattern: "tmp"
+    enabled: false
+    storage_rule: ""
+    fixed_folder: ""
+`
+
+const tomlCommented = `# CronShot 配置
+# 由部署脚本生成
+
+storage_root = 'D:\Shots' # 截图根目录
+screenshot_interval_sec = 10
+
+# 编辑器
+[[rules]]
+pattern = "(.*) - Visual Studio Code"
+enabled = true # 默认启用
+storage_rule = "(.*) - Visual Studio Code"
+fixed_folder = ""
+
+# 浏览器
+[[rules]]
+pattern = "Chrome #1"
+enabled = true
+storage_rule = ""
+fixed_folder = "web # not a comment" # 含 # 的取值
+
+# 临时规则
+[[rules]]
+pattern = 'tmp'
+enabled = false
+storage_rule = ""
+fixed_folder = ""
+`
+
+// roundTrip 加载带注释的配置文件，按 edit 修改规则后保存，返回写回的内容与重新加载的配置
+func roundTrip(t *testing.T, name, content string, edit func(rules []AppRule) []AppRule) (string, AppConfig) {
+	t.Helper()
+	s, path := loadFixture(t, name, []byte(content))
+	if err := s.Update(func(c *AppConfig) error {
+		c.Rules = edit(c.Rules)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again := loadStore(t, filepath.Dir(path))
+	return string(out), again.Snapshot()
+}
+
+// lineWith 返回包含 substr 的第一行（去除首尾空白），不存在时返回空
+func lineWith(text, substr string) string {
+	for _, l := range strings.Split(text, "\n") {
+		if strings.Contains(l, substr) {
+			return strings.TrimSpace(l)
+		}
+	}
+	return ""
+}
+
+// lineBefore 返回包含 substr 的第一行之前的非空行
+func lineBefore(text, substr string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if strings.Contains(l, substr) {
+			for j := i - 1; j >= 0; j-- {
+				if p := strings.TrimSpace(lines[j]); p != "" {
+					return p
+				}
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+func patterns(rules []AppRule) []string {
+	var out []string
+	for _, r := range rules {
+		out = append(out, r.Pattern)
+	}
+	return out
+}
+
+func TestCommentRoundTrip(t *testing.T) {
+	type edit struct {
+		name string
+		fn   func(rules []AppRule) []AppRule
+		// 写回后应保留的注释：规则标题 -> 规则上方的注释
+		above map[string]string
+		// 不应再出现的注释
+		gone []string
+		want []string
+	}
+	edits := []edit{
+		{
+			name: "change a rule",
+			fn: func(rules []AppRule) []AppRule {
+				rules[1].FixedFolder = "browser"
+				rules[2].Enabled = true
+				return rules
+			},
+			above: map[string]string{"Visual Studio Code": "# 编辑器", "Chrome #1": "# 浏览器", "tmp": "# 临时规则"},
+			want:  []string{"(.*) - Visual Studio Code", "Chrome #1", "tmp"},
+		},
+		{
+			name: "reorder rules",
+			fn: func(rules []AppRule) []AppRule {
+				return []AppRule{rules[2], rules[0], rules[1]}
+			},
+			above: map[string]string{"Visual Studio Code": "# 编辑器", "Chrome #1": "# 浏览器", "tmp": "# 临时规则"},
+			want:  []string{"tmp", "(.*) - Visual Studio Code", "Chrome #1"},
+		},
+		{
+			name: "delete a rule",
+			fn: func(rules []AppRule) []AppRule {
+				return []AppRule{rules[0], rules[2]}
+			},
+			above: map[string]string{"Visual Studio Code": "# 编辑器", "tmp": "# 临时规则"},
+			gone:  []string{"# 浏览器"},
+			want:  []string{"(.*) - Visual Studio Code", "tmp"},
+		},
+	}
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"config.yaml", yamlCommented},
+		{"config.toml", tomlCommented},
+	}
+	for _, f := range files {
+		for _, e := range edits {
+			t.Run(f.name+"/"+e.name, func(t *testing.T) {
+				out, c := roundTrip(t, f.name, f.content, e.fn)
+				if got := patterns(c.Rules); strings.Join(got, "|") != strings.Join(e.want, "|") {
+					t.Errorf("rules after reload = %q, want %q", got, e.want)
+				}
+				if !strings.HasPrefix(out, "# CronShot 配置\n# 由部署脚本生成\n") {
+					t.Errorf("header comment lost:\n%s", out)
+				}
+				if l := lineWith(out, "storage_root"); !strings.HasSuffix(l, "# 截图根目录") {
+					t.Errorf("storage_root line comment lost: %q", l)
+				}
+				if l := lineWith(out, "enabled: true # 默认启用") + lineWith(out, "enabled = true # 默认启用"); l == "" {
+					t.Errorf("rule line comment lost:\n%s", out)
+				}
+				for title, comment := range e.above {
+					got := lineBefore(out, title)
+					if strings.HasSuffix(f.name, ".toml") {
+						// TOML 的注释位于规则所在数组表头的上方
+						head := strings.LastIndex(out[:strings.Index(out, title)], "[[rules]]")
+						got = lastNonEmptyLine(out[:head])
+					}
+					if got != comment {
+						t.Errorf("comment above %q = %q, want %q\n%s", title, got, comment, out)
+					}
+				}
+				for _, g := range e.gone {
+					if strings.Contains(out, g) {
+						t.Errorf("comment %q of a deleted rule still present:\n%s", g, out)
+					}
+				}
+			})
+		}
+	}
+}
+
+// lastNonEmptyLine 返回文本中最后一个非空行
+func lastNonEmptyLine(text string) string {
+	lines := strings.Split(text, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if l := strings.TrimSpace(lines[i]); l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+func TestHashInsideTOMLString(t *testing.T) {
+	out, c := roundTrip(t, "config.toml", tomlCommented, func(rules []AppRule) []AppRule { return rules })
+	if c.Rules[1].FixedFolder != "web # not a comment" {
+		t.Errorf("fixed_folder = %q, want the # kept inside the string", c.Rules[1].FixedFolder)
+	}
+	if l := lineWith(out, "web # not a comment"); !strings.HasSuffix(l, `"web # not a comment" # 含 # 的取值`) {
+		t.Errorf("line with # in string = %q", l)
+	}
+	if n := strings.Count(out, "# 含 # 的取值"); n != 1 {
+		t.Errorf("inline comment appears %d times:\n%s", n, out)
+	}
+}
+
+func TestSplitTOMLComment(t *testing.T) {
+	tests := []struct {
+		line, code, comment string
+	}{
+		{`a = 1`, `a = 1`, ``},
+		{`a = 1 # one`, `a = 1`, `# one`},
+		{`a = "x # y"`, `a = "x # y"`, ``},
+		{`a = "x # y" # z`, `a = "x # y"`, `# z`},
+		{`a = 'x # y' # z`, `a = 'x # y'`, `# z`},
+		{`a = "say \"#\"" # z`, `a = "say \"#\""`, `# z`},
+		{`a = 'C:\' # z`, `a = 'C:\'`, `# z`},
+		{`# only`, ``, `# only`},
+	}
+	for _, tt := range tests {
+		code, comment := splitTOMLComment(tt.line)
+		if code != tt.code || comment != tt.comment {
+			t.Errorf("splitTOMLComment(%q) = (%q, %q), want (%q, %q)", tt.line, code, comment, tt.code, tt.comment)
+		}
+	}
+}
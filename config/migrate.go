@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// CurrentSchemaVersion 当前配置文件结构版本；结构调整（字段改名、拆分等）时递增并追加迁移
+const CurrentSchemaVersion = 1
+
+// migration 将配置从某个版本升级到下一个版本，直接修改解码后的 JSON 对象
+type migration func(m map[string]any) error
+
+// migrations 按起始版本排列的迁移链：migrations[i] 将版本 i 升级到 i+1
+var migrations = []migration{
+	migrateV0ToV1,
+}
+
+// migrateV0ToV1 升级没有 schema_version 的旧配置
+// 旧版本在加载时把缺失或为 0 的数值项视为默认值，此处将这些默认值写入配置，
+// 之后的版本按文件中的值原样加载（例如空白判定容差可以为 0）
+func migrateV0ToV1(m map[string]any) error {
+	defaults := map[string]any{
+		"screenshot_interval_sec": 5,
+		"dedupe_threshold":        100,
+		"idle_pause_minutes":      10,
+		"blank_tolerance":         4,
+	}
+	for k, v := range defaults {
+		if isZeroJSON(m[k]) {
+			m[k] = v
+		}
+	}
+	if s, _ := m["blank_action"].(string); s == "" {
+		m["blank_action"] = BlankActionSkip
+	}
+	return nil
+}
+
+// isZeroJSON 判断 JSON 值是否缺失或为数值 0
+func isZeroJSON(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case json.Number:
+		f, err := t.Float64()
+		return err == nil && f == 0
+	}
+	return false
+}
+
+// migrateConfig 读取配置内容的 schema_version 并依次执行迁移
+// 返回迁移后的 JSON、原始版本号；文件版本高于当前版本时不迁移，原样返回
+func migrateConfig(data []byte) ([]byte, int, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return nil, 0, err
+	}
+	from := 0
+	if v, ok := m["schema_version"]; ok {
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil, 0, fmt.Errorf("schema_version 必须为整数")
+		}
+		i, err := n.Int64()
+		if err != nil || i < 0 {
+			return nil, 0, fmt.Errorf("无效的 schema_version: %s", n)
+		}
+		from = int(i)
+	}
+	if from >= CurrentSchemaVersion {
+		return data, from, nil
+	}
+	for v := from; v < CurrentSchemaVersion; v++ {
+		if err := migrations[v](m); err != nil {
+			return nil, from, fmt.Errorf("配置从版本 %d 升级到 %d 失败: %w", v, v+1, err)
+		}
+		m["schema_version"] = v + 1
+	}
+	out, err := json.Marshal(m)
+	if err != nil {
+		return nil, from, err
+	}
+	return out, from, nil
+}
+
+// backupConfig 在迁移前保存原始配置文件为 <path>.v<version>.bak，已存在的备份不覆盖
+func backupConfig(path string, data []byte, version int) error {
+	bak := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(bak); err == nil {
+		return nil
+	}
+	return os.WriteFile(bak, data, 0644)
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata golden files")
+
+// loadFixture 将 data 写为临时配置目录中的 name（如 config.json）并加载；
+// 存储路径不存在等校验错误与测试无关，被忽略
+func loadFixture(t *testing.T, name string, data []byte) (*Store, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return loadStore(t, dir), path
+}
+
+// loadStore 加载配置目录 dir，只允许校验错误
+func loadStore(t *testing.T, dir string) *Store {
+	t.Helper()
+	s := NewStore(dir)
+	var verr ValidationError
+	if err := s.Load(); err != nil && !errors.As(err, &verr) {
+		t.Fatalf("Load: %v", err)
+	}
+	return s
+}
+
+// TestMigrateGolden 对 testdata/v0_*.json 中的每种历史配置执行 Store.Load，
+// 比较写回的配置与 <name>.golden，并检查迁移前的备份与原文件一致
+func TestMigrateGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "v0_*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures: %v", err)
+	}
+	for _, fixture := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(fixture), ".json"), func(t *testing.T) {
+			orig, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, path := loadFixture(t, "config.json", orig)
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := fixture + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("migrated config mismatch (run with -update to accept)\ngot:\n%s\nwant:\n%s", got, want)
+			}
+			bak, err := os.ReadFile(path + ".v0.bak")
+			if err != nil {
+				t.Fatalf("backup: %v", err)
+			}
+			if !bytes.Equal(bak, orig) {
+				t.Errorf("backup differs from the original file")
+			}
+		})
+	}
+}
+
+// TestMigrateCurrentVersion 当前版本的配置不迁移、不备份、不写回
+func TestMigrateCurrentVersion(t *testing.T) {
+	orig := []byte(`{"schema_version": 1, "storage_root": "D:\\Shots", "screenshot_interval_sec": 7, "blank_tolerance": 0}` + "\n")
+	s, path := loadFixture(t, "config.json", orig)
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, orig) {
+		t.Errorf("current config rewritten:\n%s", got)
+	}
+	if _, err := os.Stat(path + ".v1.bak"); !os.IsNotExist(err) {
+		t.Errorf("unexpected backup: %v", err)
+	}
+	if c := s.Snapshot(); c.BlankTolerance != 0 || c.ScreenshotIntervalSec != 7 {
+		t.Errorf("values not loaded as written: tolerance %d, interval %d", c.BlankTolerance, c.ScreenshotIntervalSec)
+	}
+}
+
+// TestMigrateFutureVersion 高于当前版本的配置保留其版本号，不迁移
+func TestMigrateFutureVersion(t *testing.T) {
+	orig := []byte(`{"schema_version": 99, "storage_root": "D:\\Shots", "new_field": true}`)
+	s, path := loadFixture(t, "config.json", orig)
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, orig) {
+		t.Errorf("future config rewritten:\n%s", got)
+	}
+	if v := s.Snapshot().SchemaVersion; v != 99 {
+		t.Errorf("schema_version = %d, want 99", v)
+	}
+}
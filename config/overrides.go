@@ -0,0 +1,256 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix 覆盖配置项的环境变量前缀，如 CRONSHOT_STORAGE_ROOT
+const EnvPrefix = "CRONSHOT_"
+
+// 配置项取值的来源，优先级由低到高
+const (
+	SourceDefault = "default" // 默认值
+	SourceFile    = "file"    // 配置文件
+	SourceEnv     = "env"     // 环境变量 CRONSHOT_*
+	SourceFlag    = "flag"    // 命令行参数
+)
+
+// Setting 可被环境变量与命令行参数覆盖的配置项（AppConfig 中的字符串、整数与布尔字段）
+// Key 为配置文件中的字段名，Env 为环境变量名，Flag 为命令行参数名（不含 --）
+type Setting struct {
+	Key   string
+	Env   string
+	Flag  string
+	field int
+}
+
+// settings 按 AppConfig 字段顺序列出可覆盖的配置项；规则等结构化字段与 schema_version 不可覆盖
+var settings = func() []Setting {
+	var out []Setting
+	t := reflect.TypeOf(AppConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch f.Type.Kind() {
+		case reflect.String, reflect.Int, reflect.Bool:
+		default:
+			continue
+		}
+		if key == "" || key == "-" || key == "schema_version" {
+			continue
+		}
+		out = append(out, Setting{
+			Key:   key,
+			Env:   EnvPrefix + strings.ToUpper(key),
+			Flag:  strings.ReplaceAll(key, "_", "-"),
+			field: i,
+		})
+	}
+	return out
+}()
+
+// Settings 返回全部可覆盖的配置项
+func Settings() []Setting {
+	return append([]Setting(nil), settings...)
+}
+
+// LookupSetting 按字段名查找配置项
+func LookupSetting(key string) (Setting, bool) {
+	for _, s := range settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// IsBool 返回配置项是否为布尔值（命令行中可省略取值）
+func (s Setting) IsBool() bool {
+	return reflect.TypeOf(AppConfig{}).Field(s.field).Type.Kind() == reflect.Bool
+}
+
+// Get 返回配置项在 c 中的取值（文本形式）
+func (s Setting) Get(c AppConfig) string {
+	v := reflect.ValueOf(c).Field(s.field)
+	switch v.Kind() {
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	}
+	return v.String()
+}
+
+// set 将文本取值写入 c 中的配置项
+func (s Setting) set(c *AppConfig, value string) error {
+	v := reflect.ValueOf(c).Elem().Field(s.field)
+	switch v.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s: must be an integer", s.Key)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s: must be true or false", s.Key)
+		}
+		v.SetBool(b)
+	default:
+		v.SetString(value)
+	}
+	return nil
+}
+
+// Override 单个配置项的覆盖值；Origin 为具体来源（环境变量名或 --参数名）
+type Override struct {
+	Key    string
+	Value  string
+	Source string
+	Origin string
+}
+
+// NewOverride 创建配置项覆盖，并将取值规范为配置项的文本形式（如 "1" -> "true"）
+func NewOverride(s Setting, value, source string) (Override, error) {
+	var c AppConfig
+	if err := s.set(&c, value); err != nil {
+		return Override{}, err
+	}
+	origin := s.Env
+	if source == SourceFlag {
+		origin = "--" + s.Flag
+	}
+	return Override{Key: s.Key, Value: s.Get(c), Source: source, Origin: origin}, nil
+}
+
+// EnvOverrides 从环境变量（os.Environ 格式）中读取 CRONSHOT_<字段名> 形式的配置项覆盖
+// 不对应配置项的 CRONSHOT_ 变量（如 CRONSHOT_HOME）被忽略；取值无法解析的变量跳过并返回错误
+func EnvOverrides(environ []string) ([]Override, error) {
+	vars := map[string]string{}
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(k, EnvPrefix) {
+			vars[k] = v
+		}
+	}
+	var out []Override
+	var errs []string
+	for _, s := range settings {
+		v, ok := vars[s.Env]
+		if !ok {
+			continue
+		}
+		o, err := NewOverride(s, v, SourceEnv)
+		if err != nil {
+			errs = append(errs, s.Env+": "+err.Error())
+			continue
+		}
+		out = append(out, o)
+	}
+	if len(errs) > 0 {
+		return out, fmt.Errorf("ignored environment overrides: %s", strings.Join(errs, "; "))
+	}
+	return out, nil
+}
+
+// overridden 记录被覆盖的配置项：覆盖后的取值与配置文件中的取值，保存时据此还原文件取值
+type overridden struct {
+	applied Override
+	file    string
+}
+
+// SetOverrides 设置本存储的配置项覆盖（后者优先），需在 Load 之前调用
+// 覆盖在每次加载（含重新加载、切换配置方案）时应用于配置文件取值之上，不写入配置文件
+func (s *Store) SetOverrides(o []Override) {
+	s.mu.Lock()
+	s.overrides = append([]Override(nil), o...)
+	s.mu.Unlock()
+}
+
+// applyOverrides 在配置上应用覆盖并返回覆盖值的校验错误（字段名附带来源，如 "screenshot_interval_sec (--screenshot-interval-sec)"）
+// fileKeys 为配置文件中出现的字段名，用于报告取值来源
+func (s *Store) applyOverrides(c *AppConfig, fileKeys map[string]bool) ValidationError {
+	s.mu.RLock()
+	overrides := s.overrides
+	s.mu.RUnlock()
+	applied := map[string]overridden{}
+	for _, o := range overrides {
+		st, ok := LookupSetting(o.Key)
+		if !ok {
+			continue
+		}
+		prev, seen := applied[o.Key]
+		file := st.Get(*c)
+		if seen {
+			file = prev.file
+		}
+		// 覆盖值已由 NewOverride 规范化，不会解析失败
+		_ = st.set(c, o.Value)
+		applied[o.Key] = overridden{applied: o, file: file}
+	}
+	// 覆盖值不合法时仍然应用，与配置文件中的取值一样随加载错误报告
+	var problems ValidationError
+	for _, fe := range c.ValidateSettings() {
+		if o, ok := applied[fe.Field]; ok {
+			problems = append(problems, FieldError{Field: fe.Field + " (" + o.applied.Origin + ")", Message: fe.Message})
+		}
+	}
+	s.mu.Lock()
+	s.fileKeys = fileKeys
+	s.overridden = applied
+	s.mu.Unlock()
+	return problems
+}
+
+// fileKeysOf 返回配置文件 JSON 顶层出现的字段名
+func fileKeysOf(data []byte) map[string]bool {
+	var m map[string]json.RawMessage
+	_ = json.Unmarshal(data, &m)
+	keys := make(map[string]bool, len(m))
+	for k := range m {
+		keys[k] = true
+	}
+	return keys
+}
+
+// persisted 返回应写入配置文件的配置：取值仍为覆盖值的配置项还原为配置文件中的取值，
+// 运行中修改过的配置项按修改后的取值保存；调用方需持有 s.mu
+func (s *Store) persisted() AppConfig {
+	c := s.cfg
+	for key, o := range s.overridden {
+		st, ok := LookupSetting(key)
+		if ok && st.Get(c) == o.applied.Value {
+			_ = st.set(&c, o.file)
+		}
+	}
+	return c
+}
+
+// EffectiveSetting 配置项的最终取值及其来源
+type EffectiveSetting struct {
+	Key    string
+	Value  string
+	Source string // default/file/env/flag
+	Origin string // 覆盖时为环境变量名或 --参数名
+}
+
+// Effective 返回全部可覆盖配置项的最终取值与来源
+func (s *Store) Effective() []EffectiveSetting {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]EffectiveSetting, 0, len(settings))
+	for _, st := range settings {
+		e := EffectiveSetting{Key: st.Key, Value: st.Get(s.cfg), Source: SourceDefault}
+		if o, ok := s.overridden[st.Key]; ok && e.Value == o.applied.Value {
+			e.Source, e.Origin = o.applied.Source, o.applied.Origin
+		} else if s.fileKeys[st.Key] {
+			e.Source = SourceFile
+		}
+		out = append(out, e)
+	}
+	return out
+}
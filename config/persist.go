@@ -0,0 +1,170 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// saveDelay 合并连续保存的等待时间：设置窗口一次保存会连续触发多次写入
+const saveDelay = 300 * time.Millisecond
+
+// SetSaveErrorHandler 设置延迟保存失败时的回调（在保存所在的协程中调用）
+func (s *Store) SetSaveErrorHandler(f func(error)) {
+	s.pendingMu.Lock()
+	s.saveErrHandler = f
+	s.pendingMu.Unlock()
+}
+
+// delayedSave 一次延迟保存；done 在写入结束后关闭，err 为写入结果
+type delayedSave struct {
+	done chan struct{}
+	err  error
+}
+
+// scheduleSave 延迟保存配置；saveDelay 内的多次调用只写入一次
+func (s *Store) scheduleSave() {
+	if s.dir == "" {
+		return
+	}
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	if s.pendingTimer != nil {
+		// 定时器已触发但尚未开始写入时，写入会读取最新配置，无需再次安排
+		if s.pendingTimer.Stop() {
+			s.pendingTimer.Reset(saveDelay)
+		}
+		return
+	}
+	s.pendingSave = &delayedSave{done: make(chan struct{})}
+	s.pendingTimer = time.AfterFunc(saveDelay, func() {
+		s.pendingMu.Lock()
+		ds := s.pendingSave
+		s.pendingTimer, s.pendingSave, s.inflightSave = nil, nil, ds
+		onErr := s.saveErrHandler
+		s.pendingMu.Unlock()
+		ds.err = s.Save()
+		s.pendingMu.Lock()
+		if s.inflightSave == ds {
+			s.inflightSave = nil
+		}
+		s.pendingMu.Unlock()
+		close(ds.done)
+		if ds.err != nil && onErr != nil {
+			onErr(ds.err)
+		}
+	})
+}
+
+// Flush 立即写入尚未保存的配置，并等待正在进行的延迟保存结束，返回其错误；
+// 退出前调用，避免丢失延迟保存的改动或在写入中途退出
+func (s *Store) Flush() error {
+	s.pendingMu.Lock()
+	var stopped, wait *delayedSave
+	if s.pendingTimer != nil {
+		if s.pendingTimer.Stop() {
+			stopped = s.pendingSave
+			s.pendingTimer, s.pendingSave = nil, nil
+		} else {
+			// 定时器已触发，写入即将开始
+			wait = s.pendingSave
+		}
+	}
+	if wait == nil {
+		wait = s.inflightSave
+	}
+	s.pendingMu.Unlock()
+	if stopped != nil {
+		// 与执行中的写入由 saveMu 串行，本次写入包含最新配置
+		stopped.err = s.Save()
+		close(stopped.done)
+		return stopped.err
+	}
+	if wait != nil {
+		<-wait.done
+		return wait.err
+	}
+	return nil
+}
+
+// Save 立即将当前配置以原子方式写入当前配置方案的文件（格式由扩展名决定，YAML/TOML 保留文件中的注释）
+// 修改通过 Update 合并后延迟写入，退出前调用 Flush；环境变量与命令行参数的覆盖值不写入文件
+func (s *Store) Save() error {
+	if s.dir == "" {
+		return nil
+	}
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	p := s.configPath()
+	prev, _ := os.ReadFile(p)
+	s.mu.RLock()
+	data, err := encodeConfigFile(p, s.persisted(), prev)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(p, data); err != nil {
+		return err
+	}
+	s.markSynced(data)
+	return nil
+}
+
+// backupPath 返回滚动备份文件路径
+func backupPath(path string) string { return path + ".bak" }
+
+// writeFileAtomic 先写入同目录下的临时文件并同步到磁盘，再重命名覆盖目标文件，
+// 避免崩溃或并发写入留下截断的配置；覆盖前将当前可解析的配置复制为 .bak
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // 重命名成功后为空操作
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// 仅在当前文件完好时轮换备份，损坏的文件不覆盖已有的好备份
+	if cur, err := os.ReadFile(path); err == nil && validConfigData(path, cur) {
+		_ = os.WriteFile(backupPath(path), cur, 0644)
+	}
+	return os.Rename(tmpName, path)
+}
+
+// errCorruptConfig 配置文件损坏且没有可用的备份；调用方应改用默认配置
+var errCorruptConfig = errors.New("配置文件已损坏")
+
+// readConfigFile 读取并解析配置文件，内容损坏时尝试从 .bak 恢复
+// 损坏的文件另存为 <path>.corrupt-<时间>，并返回说明错误；recovered 表示内容来自备份
+func readConfigFile(path string) (data []byte, recovered bool, err error) {
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if validConfigData(path, data) {
+		return data, false, nil
+	}
+	// 保留损坏的文件，之后的保存会覆盖原路径
+	corrupt := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102_150405"))
+	_ = os.WriteFile(corrupt, data, 0644)
+	bak, bakErr := os.ReadFile(backupPath(path))
+	if bakErr != nil || !validConfigData(path, bak) {
+		return nil, false, fmt.Errorf("%w且没有可用的备份，使用默认配置（损坏的文件另存为 %s）", errCorruptConfig, filepath.Base(corrupt))
+	}
+	return bak, true, fmt.Errorf("配置文件已损坏，已从备份恢复（损坏的文件另存为 %s）", filepath.Base(corrupt))
+}
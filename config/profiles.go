@@ -0,0 +1,209 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cron-shot/utils"
+)
+
+// DefaultProfile 默认配置方案，对应配置目录下的 config.json（或 config.yaml、config.toml）
+const DefaultProfile = "default"
+
+// profilesDirName 其它配置方案所在的子目录，每个方案为 profiles/<名称>.json（或 .yaml、.toml）
+const profilesDirName = "profiles"
+
+// profileStateName 记录当前配置方案的文件
+const profileStateName = "profile.json"
+
+// errMemoryProfiles 内存存储不支持配置方案
+var errMemoryProfiles = errors.New("memory store has no profiles")
+
+// profileState 持久化的配置方案选择
+type profileState struct {
+	Active string `json:"active"`
+}
+
+// profilePath 返回配置方案对应的配置文件路径：按 configExts 的顺序取已存在的文件，都不存在时为 .json
+func (s *Store) profilePath(name string) string {
+	base := filepath.Join(s.dir, profilesDirName, name)
+	if name == DefaultProfile {
+		base = filepath.Join(s.dir, "config")
+	}
+	for _, ext := range configExts {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext
+		}
+	}
+	return base + ".json"
+}
+
+// trimConfigExt 去掉配置文件扩展名，不是配置文件时 ok 为 false
+func trimConfigExt(file string) (name string, ok bool) {
+	for _, ext := range configExts {
+		if strings.HasSuffix(strings.ToLower(file), ext) {
+			return file[:len(file)-len(ext)], true
+		}
+	}
+	return file, false
+}
+
+// ValidateProfileName 校验配置方案名：不能为空，且只能包含可用作文件名的字符
+func ValidateProfileName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("profile name must not be empty")
+	}
+	if utils.SanitizeFolderName(name) != name || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	return nil
+}
+
+// ActiveProfile 返回当前配置方案名
+func (s *Store) ActiveProfile() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.profile
+}
+
+// readProfileState 读取持久化的配置方案；文件缺失、无效或方案已不存在时返回默认方案
+func (s *Store) readProfileState() string {
+	if s.dir == "" {
+		return DefaultProfile
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, profileStateName))
+	if err != nil {
+		return DefaultProfile
+	}
+	var st profileState
+	if json.Unmarshal(data, &st) != nil || ValidateProfileName(st.Active) != nil {
+		return DefaultProfile
+	}
+	if !s.profileExists(st.Active) {
+		return DefaultProfile
+	}
+	return st.Active
+}
+
+// writeProfileState 持久化当前配置方案
+func (s *Store) writeProfileState(name string) error {
+	data, _ := json.MarshalIndent(profileState{Active: name}, "", "  ")
+	return os.WriteFile(filepath.Join(s.dir, profileStateName), append(data, '\n'), 0644)
+}
+
+// ListProfiles 返回所有配置方案名，默认方案在前，其余按名称排序
+func (s *Store) ListProfiles() []string {
+	names := []string{DefaultProfile}
+	if s.dir == "" {
+		return names
+	}
+	entries, _ := os.ReadDir(filepath.Join(s.dir, profilesDirName))
+	seen := map[string]bool{}
+	var others []string
+	for _, e := range entries {
+		name, ok := trimConfigExt(e.Name())
+		if e.IsDir() || !ok || seen[name] || name == DefaultProfile || ValidateProfileName(name) != nil {
+			continue
+		}
+		seen[name] = true
+		others = append(others, name)
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+// profileExists 判断配置方案是否存在；默认方案始终存在
+func (s *Store) profileExists(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	if s.dir == "" {
+		return false
+	}
+	_, err := os.Stat(s.profilePath(name))
+	return err == nil
+}
+
+// CreateProfile 以 from 方案的当前配置为起点创建新方案；from 为空时复制当前方案
+// 新方案沿用 from 方案的文件格式与注释
+func (s *Store) CreateProfile(name, from string) error {
+	if s.dir == "" {
+		return errMemoryProfiles
+	}
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if s.profileExists(name) {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	if from == "" {
+		from = s.ActiveProfile()
+	}
+	if !s.profileExists(from) {
+		return fmt.Errorf("profile %q not found", from)
+	}
+	src := s.profilePath(from)
+	dst := filepath.Join(s.dir, profilesDirName, name+filepath.Ext(src))
+	data, err := os.ReadFile(src)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if from == s.ActiveProfile() {
+		// 当前方案以内存中的配置为准，包括尚未写入的修改（覆盖值除外）
+		s.mu.RLock()
+		data, err = encodeConfigFile(src, s.persisted(), data)
+		s.mu.RUnlock()
+		if err != nil {
+			return err
+		}
+	}
+	if len(data) == 0 {
+		data = []byte("{}\n")
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
+
+// DeleteProfile 删除配置方案；不能删除默认方案或当前方案
+func (s *Store) DeleteProfile(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("cannot delete the default profile")
+	}
+	if name == s.ActiveProfile() {
+		return fmt.Errorf("cannot delete the active profile %q", name)
+	}
+	if !s.profileExists(name) {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	p := s.profilePath(name)
+	_ = os.Remove(backupPath(p))
+	return os.Remove(p)
+}
+
+// SwitchProfile 切换到指定配置方案：先写入当前方案尚未保存的修改，再加载新方案并记录选择
+// 返回新方案的加载错误（含校验错误），与 Load 相同
+func (s *Store) SwitchProfile(name string) error {
+	if s.dir == "" {
+		return errMemoryProfiles
+	}
+	if !s.profileExists(name) {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	if err := s.Flush(); err != nil {
+		return err
+	}
+	if err := s.writeProfileState(name); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.profile = name
+	s.mu.Unlock()
+	return s.Load()
+}
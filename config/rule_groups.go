@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RuleGroup 命名规则组：组内规则随组整体启用或停用
+// Name: 组名；Enabled: 手动启用；Schedule: 可选时间表，处于时间段内时自动启用
+type RuleGroup struct {
+	Name     string         `json:"name"`
+	Enabled  bool           `json:"enabled"`
+	Schedule *GroupSchedule `json:"schedule,omitempty"`
+}
+
+// GroupSchedule 规则组自动启用的时间表
+// Days: 生效的星期（0=周日 … 6=周六，为空表示每天）；Start/End: 每日起止时间（HH:MM），
+// End 早于 Start 时表示跨越午夜
+type GroupSchedule struct {
+	Days  []int  `json:"days,omitempty"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// parseClock 解析 HH:MM，返回距离零点的分钟数
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("时间 %q 无效，应为 HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate 校验时间表格式
+func (s *GroupSchedule) Validate() error {
+	if _, err := parseClock(s.Start); err != nil {
+		return err
+	}
+	if _, err := parseClock(s.End); err != nil {
+		return err
+	}
+	for _, d := range s.Days {
+		if d < 0 || d > 6 {
+			return fmt.Errorf("星期 %d 无效，应为 0-6", d)
+		}
+	}
+	return nil
+}
+
+// Active 判断时间 t 是否处于时间表内；格式错误时视为不生效
+// 跨午夜的时段按开始当天的星期判断
+func (s *GroupSchedule) Active(t time.Time) bool {
+	if s == nil {
+		return false
+	}
+	start, err1 := parseClock(s.Start)
+	end, err2 := parseClock(s.End)
+	if err1 != nil || err2 != nil || start == end {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	var in bool
+	if start < end {
+		in = now >= start && now < end
+	} else {
+		// 跨午夜：午夜之后的部分属于前一天的时段
+		in = now >= start || now < end
+		if now < end {
+			day = (day + 6) % 7
+		}
+	}
+	if !in {
+		return false
+	}
+	if len(s.Days) == 0 {
+		return true
+	}
+	for _, d := range s.Days {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Active 判断规则组在时间 t 是否生效：手动启用或处于时间表内
+func (g RuleGroup) Active(t time.Time) bool {
+	return g.Enabled || g.Schedule.Active(t)
+}
+
+// GroupActive 判断规则所属组在时间 t 是否生效
+// 规则未分组或所属组未定义（如导入的规则包）时视为生效
+func GroupActive(groups []RuleGroup, name string, t time.Time) bool {
+	if name == "" {
+		return true
+	}
+	for _, g := range groups {
+		if g.Name == name {
+			return g.Active(t)
+		}
+	}
+	return true
+}
+
+// GetRuleGroups 返回规则组切片副本
+func GetRuleGroups() []RuleGroup { return Snapshot().RuleGroups }
+
+// SetRuleGroups 设置规则组并持久化；已编译规则随之失效
+func SetRuleGroups(g []RuleGroup) {
+	set(func(c *AppConfig) { c.RuleGroups = append([]RuleGroup(nil), g...) })
+}
+
+// SetRuleGroupEnabled 在默认存储中手动启用或停用规则组
+func SetRuleGroupEnabled(name string, enabled bool) error {
+	return Default().SetRuleGroupEnabled(name, enabled)
+}
+
+// SetRuleGroupEnabled 手动启用或停用规则组并立即保存；组不存在时返回错误
+func (s *Store) SetRuleGroupEnabled(name string, enabled bool) error {
+	err := s.Update(func(c *AppConfig) error {
+		for i := range c.RuleGroups {
+			if c.RuleGroups[i].Name == name {
+				c.RuleGroups[i].Enabled = enabled
+				return nil
+			}
+		}
+		return fmt.Errorf("rule group %q not found", name)
+	})
+	if err != nil {
+		return err
+	}
+	return s.Flush()
+}
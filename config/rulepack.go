@@ -0,0 +1,141 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// RulePackVersion 当前规则包格式版本
+const RulePackVersion = 1
+
+// 规则包合并方式
+const (
+	MergeAppend         = "append"          // 追加到现有规则之后
+	MergeReplace        = "replace"         // 替换全部现有规则
+	MergeSkipDuplicates = "skip_duplicates" // 追加，但跳过与现有规则匹配条件相同的规则
+)
+
+// RulePack 可独立分享的规则集合文件（JSON 或 YAML），不包含机器相关的设置
+// Version: 格式版本；Name/Description: 规则包说明；Rules: 规则列表
+type RulePack struct {
+	Version     int       `json:"version"`
+	Name        string    `json:"name,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Rules       []AppRule `json:"rules"`
+}
+
+// isYAMLPath 按扩展名判断是否为 YAML 文件
+func isYAMLPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// ExportRulePack 将规则写入规则包文件；扩展名为 .yaml/.yml 时写 YAML，否则写 JSON
+func ExportRulePack(path, name string, rules []AppRule) error {
+	pack := RulePack{Version: RulePackVersion, Name: name, Rules: rules}
+	data, err := json.MarshalIndent(pack, "", "  ")
+	if err != nil {
+		return err
+	}
+	if isYAMLPath(path) {
+		if data, err = jsonToYAML(data); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReadRulePack 读取并校验规则包文件；未知字段、版本不受支持或规则非法时返回错误
+// 规则校验错误以 ValidationError 返回，字段路径形如 rules[2].pattern
+func ReadRulePack(path string) (*RulePack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isYAMLPath(path) {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, err
+		}
+	}
+	var pack RulePack
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&pack); err != nil {
+		return nil, fmt.Errorf("invalid rule pack: %w", err)
+	}
+	if pack.Version < 1 || pack.Version > RulePackVersion {
+		return nil, fmt.Errorf("unsupported rule pack version %d", pack.Version)
+	}
+	var errs ValidationError
+	for i, r := range pack.Rules {
+		errs = append(errs, prefixed(fmt.Sprintf("rules[%d]", i), ValidateRule(r))...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &pack, nil
+}
+
+// MergeRules 按合并方式将 incoming 合并到 existing，返回合并结果与新增规则数
+func MergeRules(existing, incoming []AppRule, strategy string) ([]AppRule, int, error) {
+	switch strategy {
+	case MergeReplace:
+		return append([]AppRule(nil), incoming...), len(incoming), nil
+	case MergeAppend, "":
+		return append(append([]AppRule(nil), existing...), incoming...), len(incoming), nil
+	case MergeSkipDuplicates:
+		out := append([]AppRule(nil), existing...)
+		added := 0
+		for _, r := range incoming {
+			dup := false
+			for _, e := range out {
+				if sameRuleMatch(e, r) {
+					dup = true
+					break
+				}
+			}
+			if !dup {
+				out = append(out, r)
+				added++
+			}
+		}
+		return out, added, nil
+	}
+	return nil, 0, fmt.Errorf("unknown merge strategy %q", strategy)
+}
+
+// sameRuleMatch 判断两条规则的匹配条件是否相同（标题、附加条件与排除标记）
+func sameRuleMatch(a, b AppRule) bool {
+	return a.Pattern == b.Pattern && a.Exclude == b.Exclude && reflect.DeepEqual(a.Match, b.Match)
+}
+
+// ImportRulePack 读取规则包并按合并方式写入默认存储的规则，返回新增规则数
+func ImportRulePack(path, strategy string) (int, error) {
+	return Default().ImportRulePack(path, strategy)
+}
+
+// ImportRulePack 读取规则包并按合并方式写入当前规则，返回新增规则数
+func (s *Store) ImportRulePack(path, strategy string) (int, error) {
+	pack, err := ReadRulePack(path)
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	err = s.Update(func(c *AppConfig) error {
+		merged, n, err := MergeRules(c.Rules, pack.Rules, strategy)
+		if err != nil {
+			return err
+		}
+		c.Rules, added = merged, n
+		return nil
+	})
+	return added, err
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Provider 配置的读取、事务式修改与变更订阅
+// 截图逻辑、界面与托盘通过构造函数接收 Provider，测试可替换为 NewMemoryStore 创建的内存存储
+type Provider interface {
+	// Snapshot 返回当前配置的副本
+	Snapshot() AppConfig
+	// Update 在配置副本上执行 fn，fn 返回 nil 时整体提交并延迟保存，返回错误时不做任何修改
+	// fn 可以调用 Snapshot，但不能再调用 Update
+	Update(fn func(c *AppConfig) error) error
+	// Subscribe 订阅配置变化（修改、重新加载、切换配置方案），返回取消订阅的函数
+	// fn 在引起变化的协程中调用
+	Subscribe(fn func(old, cur AppConfig)) (cancel func())
+}
+
+// Store 基于配置目录的配置存储：当前配置、配置方案、延迟的原子保存与文件监听
+// dir 为空的存储只在内存中保存配置，不读写任何文件
+type Store struct {
+	dir string
+
+	// updateMu 串行化对配置的整体修改（Update 与重新加载），执行修改函数期间不持有 mu
+	updateMu sync.Mutex
+	mu       sync.RWMutex
+	cfg      AppConfig
+	profile  string
+	// loadErr 最近一次加载配置时的错误（含从备份恢复、校验失败）
+	loadErr error
+	// overrides 环境变量与命令行参数的覆盖；overridden 与 fileKeys 记录最近一次加载时的覆盖结果与文件中出现的字段
+	overrides  []Override
+	overridden map[string]overridden
+	fileKeys   map[string]bool
+
+	// saveMu 串行化配置文件写入
+	saveMu sync.Mutex
+	// pendingMu 保护延迟保存的定时器、待执行与执行中的延迟保存以及保存失败回调
+	pendingMu      sync.Mutex
+	pendingTimer   *time.Timer
+	pendingSave    *delayedSave
+	inflightSave   *delayedSave
+	saveErrHandler func(error)
+
+	// syncedHash 最近一次读取或写入的配置内容摘要，用于忽略自身的写入
+	syncedMu   sync.Mutex
+	syncedHash [sha256.Size]byte
+
+	subsMu  sync.Mutex
+	subs    map[int]func(old, cur AppConfig)
+	nextSub int
+}
+
+var _ Provider = (*Store)(nil)
+
+// NewStore 创建以 dir 为配置目录的存储，使用上次记录的配置方案；需调用 Load 读取配置文件
+func NewStore(dir string) *Store {
+	s := &Store{dir: dir, cfg: defaultConfig(), profile: DefaultProfile}
+	s.profile = s.readProfileState()
+	return s
+}
+
+// NewMemoryStore 创建只在内存中保存配置的存储，不读写任何文件
+func NewMemoryStore(c AppConfig) *Store {
+	return &Store{cfg: c, profile: DefaultProfile}
+}
+
+// Dir 返回存储的配置目录；内存存储返回空字符串
+func (s *Store) Dir() string { return s.dir }
+
+// copyConfig 深复制配置，切片与指针字段不与原配置共享
+func copyConfig(c AppConfig) AppConfig {
+	c.Rules = append([]AppRule(nil), c.Rules...)
+	for i := range c.Rules {
+		c.Rules[i].Rect = copyRect(c.Rules[i].Rect)
+		if m := c.Rules[i].Match; m != nil {
+			cp := *m
+			c.Rules[i].Match = &cp
+		}
+	}
+	c.ScreenRules = append([]ScreenRule(nil), c.ScreenRules...)
+	for i := range c.ScreenRules {
+		c.ScreenRules[i].Rect = copyRect(c.ScreenRules[i].Rect)
+	}
+	c.RuleGroups = append([]RuleGroup(nil), c.RuleGroups...)
+	for i := range c.RuleGroups {
+		if sch := c.RuleGroups[i].Schedule; sch != nil {
+			cp := *sch
+			cp.Days = append([]int(nil), sch.Days...)
+			c.RuleGroups[i].Schedule = &cp
+		}
+	}
+	return c
+}
+
+func copyRect(r *CaptureRect) *CaptureRect {
+	if r == nil {
+		return nil
+	}
+	cp := *r
+	return &cp
+}
+
+// Snapshot 返回当前配置的副本，可修改后调用 Validate 预先校验
+func (s *Store) Snapshot() AppConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyConfig(s.cfg)
+}
+
+// Update 在配置副本上执行 fn 并整体提交，随后延迟保存并通知订阅者
+// fn 执行期间不持有读锁，可以调用 Snapshot；对配置的修改彼此串行，fn 内不能再调用 Update 或重新加载配置
+func (s *Store) Update(fn func(c *AppConfig) error) error {
+	s.updateMu.Lock()
+	old := s.Snapshot()
+	c := copyConfig(old)
+	if err := fn(&c); err != nil {
+		s.updateMu.Unlock()
+		return err
+	}
+	s.mu.Lock()
+	s.cfg = copyConfig(c)
+	s.mu.Unlock()
+	s.updateMu.Unlock()
+	s.scheduleSave()
+	s.notify(old, c)
+	return nil
+}
+
+// rulesChanged 判断规则或规则组是否变化
+func rulesChanged(old, cur AppConfig) bool {
+	return !reflect.DeepEqual(old.Rules, cur.Rules) || !reflect.DeepEqual(old.RuleGroups, cur.RuleGroups)
+}
+
+// Subscribe 订阅配置变化，返回取消订阅的函数
+func (s *Store) Subscribe(fn func(old, cur AppConfig)) (cancel func()) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	if s.subs == nil {
+		s.subs = map[int]func(old, cur AppConfig){}
+	}
+	id := s.nextSub
+	s.nextSub++
+	s.subs[id] = fn
+	return func() {
+		s.subsMu.Lock()
+		delete(s.subs, id)
+		s.subsMu.Unlock()
+	}
+}
+
+// notify 按订阅顺序通知配置变化
+func (s *Store) notify(old, cur AppConfig) {
+	s.subsMu.Lock()
+	ids := make([]int, 0, len(s.subs))
+	for id := range s.subs {
+		ids = append(ids, id)
+	}
+	fns := make([]func(old, cur AppConfig), 0, len(ids))
+	sort.Ints(ids)
+	for _, id := range ids {
+		fns = append(fns, s.subs[id])
+	}
+	s.subsMu.Unlock()
+	for _, fn := range fns {
+		fn(old, cur)
+	}
+}
+
+// LoadError 返回最近一次加载配置时的错误；从备份恢复时同样返回说明错误
+func (s *Store) LoadError() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.loadErr
+}
+
+// configPath 返回当前配置方案的配置文件路径：默认方案为 <配置目录>/config.json
+func (s *Store) configPath() string {
+	return s.profilePath(s.ActiveProfile())
+}
+
+// Load 读取当前配置方案的配置文件（JSON），替换内存中的配置并通知订阅者
+// 旧版本配置先备份为 config.json.v<N>.bak，再依次迁移到当前版本并写回；
+// 文件损坏时从 config.json.bak 恢复，并返回说明错误；配置校验失败时仍然加载，并返回 ValidationError
+func (s *Store) Load() error {
+	err := s.load()
+	s.mu.Lock()
+	s.loadErr = err
+	s.mu.Unlock()
+	return err
+}
+
+func (s *Store) load() error {
+	if s.dir == "" {
+		return nil
+	}
+	p := s.configPath()
+	data, recovered, readErr := readConfigFile(p)
+	if data == nil {
+		// 文件不存在，或已损坏且没有备份：使用默认配置，覆盖项照常生效
+		if os.IsNotExist(readErr) || errors.Is(readErr, errCorruptConfig) {
+			c := defaultConfig()
+			verr := s.applyOverrides(&c, nil)
+			s.replace(c)
+			if os.IsNotExist(readErr) {
+				readErr = nil
+			}
+			var problems error
+			if len(verr) > 0 {
+				problems = verr
+			}
+			return errors.Join(readErr, problems)
+		}
+		return readErr
+	}
+	if !recovered {
+		s.markSynced(data)
+	}
+	raw, err := decodeConfigFile(p, data)
+	if err != nil {
+		return err
+	}
+	migrated, from, err := migrateConfig(raw)
+	if err != nil {
+		return err
+	}
+	c := defaultConfig()
+	if err := json.Unmarshal(migrated, &c); err != nil {
+		return err
+	}
+	// 按文件中的原始值校验，问题随返回的错误报告，而不是被默认值掩盖
+	verr := c.Validate()
+	// 仅保留对取值无意义的项的保护，其余字段按文件原样加载
+	if c.StorageRoot == "" {
+		c.StorageRoot = GetDefaultStorageRoot()
+	}
+	if c.ScreenshotIntervalSec <= 0 {
+		c.ScreenshotIntervalSec = 5
+	}
+	if c.BlankAction == "" {
+		c.BlankAction = BlankActionSkip
+	}
+	// 高于当前版本的配置按已知字段加载，保留其版本号，避免写回时被标记为旧版本
+	if from < CurrentSchemaVersion {
+		c.SchemaVersion = CurrentSchemaVersion
+	}
+	// 环境变量与命令行参数的覆盖位于文件取值之上
+	verr = append(verr, s.applyOverrides(&c, fileKeysOf(migrated))...)
+	s.replace(c)
+	if from < CurrentSchemaVersion {
+		if err := backupConfig(p, data, from); err != nil {
+			return err
+		}
+	}
+	if from < CurrentSchemaVersion || recovered {
+		if err := s.Save(); err != nil {
+			return err
+		}
+	}
+	var problems error
+	if len(verr) > 0 {
+		problems = verr
+	}
+	return errors.Join(readErr, problems)
+}
+
+// replace 整体替换内存中的配置（不保存），并通知订阅者
+func (s *Store) replace(c AppConfig) {
+	s.updateMu.Lock()
+	s.mu.Lock()
+	old := copyConfig(s.cfg)
+	s.cfg = c
+	s.mu.Unlock()
+	s.updateMu.Unlock()
+	s.notify(old, copyConfig(c))
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLoadCorruptWithoutBackup 损坏且没有备份的配置文件回退到默认配置，覆盖项照常生效
+func TestLoadCorruptWithoutBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	st, ok := LookupSetting("screenshot_interval_sec")
+	if !ok {
+		t.Fatal("setting screenshot_interval_sec not found")
+	}
+	o, err := NewOverride(st, "42", SourceEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewStore(dir)
+	s.SetOverrides([]Override{o})
+
+	err = s.Load()
+	if !errors.Is(err, errCorruptConfig) {
+		t.Fatalf("Load = %v, want a corrupt config error", err)
+	}
+	want := defaultConfig()
+	want.ScreenshotIntervalSec = 42
+	if c := s.Snapshot(); c.ScreenshotIntervalSec != 42 || c.StorageRoot != want.StorageRoot || len(c.Rules) != len(want.Rules) {
+		t.Errorf("config after load = %+v, want defaults with the override", c)
+	}
+	if m, _ := filepath.Glob(path + ".corrupt-*"); len(m) != 1 {
+		t.Errorf("corrupt file not preserved: %v", m)
+	}
+}
+
+// TestUpdateDeepCopies 通过 Update 修改嵌套字段不影响之前取得的快照，订阅者能看到变化
+func TestUpdateDeepCopies(t *testing.T) {
+	c := defaultConfig()
+	c.Rules = []AppRule{{Pattern: "a", Match: &WindowMatch{Class: "Old"}, Rect: &CaptureRect{X: 1}}}
+	c.ScreenRules = []ScreenRule{{Name: "s", Rect: &CaptureRect{X: 1}}}
+	c.RuleGroups = []RuleGroup{{Name: "g", Schedule: &GroupSchedule{Days: []int{1, 2}, Start: "09:00", End: "18:00"}}}
+	s := NewMemoryStore(c)
+	before := s.Snapshot()
+
+	var changed bool
+	cancel := s.Subscribe(func(old, cur AppConfig) { changed = rulesChanged(old, cur) })
+	defer cancel()
+	err := s.Update(func(c *AppConfig) error {
+		c.Rules[0].Match.Class = "New"
+		c.Rules[0].Rect.X = 2
+		c.ScreenRules[0].Rect.X = 2
+		c.RuleGroups[0].Schedule.Days[0] = 5
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.Rules[0].Match.Class != "Old" || before.Rules[0].Rect.X != 1 || before.ScreenRules[0].Rect.X != 1 || before.RuleGroups[0].Schedule.Days[0] != 1 {
+		t.Errorf("earlier snapshot modified: %+v", before)
+	}
+	if !changed {
+		t.Errorf("subscriber did not see the nested change")
+	}
+	after := s.Snapshot()
+	if after.Rules[0].Match.Class != "New" || after.RuleGroups[0].Schedule.Days[0] != 5 {
+		t.Errorf("update not applied: %+v", after)
+	}
+	// 修改新的快照同样不影响存储中的配置
+	after.Rules[0].Match.Class = "Other"
+	if s.Snapshot().Rules[0].Match.Class != "New" {
+		t.Errorf("snapshot shares the stored rule match")
+	}
+}
+
+// TestUpdateCallsSnapshot 修改函数内调用 Snapshot 不会死锁，读到的是修改前的配置
+func TestUpdateCallsSnapshot(t *testing.T) {
+	s := NewMemoryStore(defaultConfig())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Update(func(c *AppConfig) error {
+			if s.Snapshot().ScreenshotIntervalSec != c.ScreenshotIntervalSec {
+				return errors.New("snapshot differs from the config being updated")
+			}
+			c.ScreenshotIntervalSec = 9
+			return nil
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Update deadlocked when fn called Snapshot")
+	}
+	if v := s.Snapshot().ScreenshotIntervalSec; v != 9 {
+		t.Errorf("interval = %d, want 9", v)
+	}
+}
+
+// TestLoadRestoresFromBackup 配置文件损坏时从 .bak 恢复并写回，损坏的文件另存
+func TestLoadRestoresFromBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(backupPath(path), []byte(`{"schema_version": 1, "screenshot_interval_sec": 7}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewStore(dir)
+	err := s.Load()
+	if err == nil || errors.Is(err, errCorruptConfig) {
+		t.Fatalf("Load = %v, want a restored-from-backup error", err)
+	}
+	if v := s.Snapshot().ScreenshotIntervalSec; v != 7 {
+		t.Errorf("interval = %d, want 7 from the backup", v)
+	}
+	data, _ := os.ReadFile(path)
+	if !validConfigData(path, data) {
+		t.Errorf("config not rewritten from the backup:\n%s", data)
+	}
+	if m, _ := filepath.Glob(path + ".corrupt-*"); len(m) != 1 {
+		t.Errorf("corrupt file not preserved: %v", m)
+	}
+}
+
+// TestSaveCoalesces 连续多次修改只写入一次：文件在延迟内保持不变，.bak 为修改前的内容
+func TestSaveCoalesces(t *testing.T) {
+	orig := []byte(`{"schema_version": 1, "screenshot_interval_sec": 5}`)
+	s, path := loadFixture(t, "config.json", orig)
+	for _, n := range []int{11, 12, 13} {
+		n := n
+		if err := s.Update(func(c *AppConfig) error { c.ScreenshotIntervalSec = n; return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, orig) {
+		t.Errorf("config written before the save delay:\n%s", got)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if v := loadStore(t, filepath.Dir(path)).Snapshot().ScreenshotIntervalSec; v != 13 {
+		t.Errorf("saved interval = %d, want 13", v)
+	}
+	if bak, _ := os.ReadFile(backupPath(path)); !bytes.Equal(bak, orig) {
+		t.Errorf("backup is not the original file, the changes were saved more than once:\n%s", bak)
+	}
+}
+
+// TestFlushWaitsForInflightSave 定时器已触发、写入尚未结束时 Flush 等待写入完成
+func TestFlushWaitsForInflightSave(t *testing.T) {
+	s, path := loadFixture(t, "config.json", []byte(`{"schema_version": 1}`))
+	// 持有写入锁，使延迟保存停在写入之前
+	s.saveMu.Lock()
+	if err := s.Update(func(c *AppConfig) error { c.ScreenshotIntervalSec = 21; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(2 * time.Second); ; {
+		s.pendingMu.Lock()
+		started := s.inflightSave != nil
+		s.pendingMu.Unlock()
+		if started {
+			break
+		}
+		if time.Now().After(deadline) {
+			s.saveMu.Unlock()
+			t.Fatal("delayed save did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Flush() }()
+	select {
+	case err := <-done:
+		s.saveMu.Unlock()
+		t.Fatalf("Flush returned %v before the in-flight save finished", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	s.saveMu.Unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if v := loadStore(t, filepath.Dir(path)).Snapshot().ScreenshotIntervalSec; v != 21 {
+		t.Errorf("saved interval = %d, want 21", v)
+	}
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cron-shot/sys_utils"
+	"cron-shot/utils"
+)
+
+// FieldError 描述单个配置字段的校验错误；Field 为字段路径（如 rules[2].match.class）
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError 多个字段校验错误的集合
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// prefixed 为字段错误添加路径前缀
+func prefixed(prefix string, errs []FieldError) []FieldError {
+	out := make([]FieldError, 0, len(errs))
+	for _, e := range errs {
+		out = append(out, FieldError{Field: prefix + "." + e.Field, Message: e.Message})
+	}
+	return out
+}
+
+// ValidateRule 校验单条窗口规则：正则可编译、目标与区域合法、匹配条件取值合理
+func ValidateRule(r AppRule) []FieldError {
+	var errs []FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	regex := func(field, pattern string) {
+		if pattern == "" {
+			return
+		}
+		if _, err := utils.CompileRegex(pattern); err != nil {
+			add(field, "正则表达式无效: %v", err)
+		}
+	}
+	if r.Pattern == "" && (r.Match == nil || *r.Match == (WindowMatch{Mode: r.Match.Mode})) {
+		add("pattern", "未设置匹配条件时不能为空")
+	}
+	regex("pattern", r.Pattern)
+	storage := strings.TrimSpace(r.StorageRule)
+	re, err := utils.CompileRegex(storage)
+	if err != nil {
+		add("storage_rule", "正则表达式无效: %v", err)
+	} else if tmpl := strings.TrimSpace(r.FolderTemplate); tmpl != "" {
+		if storage == "" {
+			re = nil
+		}
+		for _, name := range utils.UnknownTemplateNames(tmpl, re) {
+			add("folder_template", "存储规则中没有捕获组 {%s}", name)
+		}
+	}
+	if r.Monitor < 0 {
+		add("monitor", "不能小于 0")
+	}
+	switch strings.ToLower(strings.TrimSpace(r.Target)) {
+	case "", TargetWindow, TargetMonitor, TargetAllMonitors:
+	case TargetRect:
+		if r.Rect == nil || r.Rect.Width <= 0 || r.Rect.Height <= 0 {
+			add("rect", "截图目标为 rect 时宽度和高度必须大于 0")
+		}
+	default:
+		add("target", "未知的截图目标 %q", r.Target)
+	}
+	if m := r.Match; m != nil {
+		switch strings.ToLower(strings.TrimSpace(m.Mode)) {
+		case "", MatchAll, MatchAny:
+		default:
+			add("match.mode", "必须为 %q 或 %q", MatchAll, MatchAny)
+		}
+		regex("match.class", m.Class)
+		regex("match.exe_path", m.ExePath)
+		regex("match.cmdline", m.CmdLine)
+		regex("match.parent", m.Parent)
+		for _, v := range []struct {
+			field string
+			n     int
+		}{{"match.min_width", m.MinWidth}, {"match.max_width", m.MaxWidth}, {"match.min_height", m.MinHeight}, {"match.max_height", m.MaxHeight}, {"match.monitor", m.Monitor}} {
+			if v.n < 0 {
+				add(v.field, "不能小于 0")
+			}
+		}
+		if m.MaxWidth > 0 && m.MinWidth > m.MaxWidth {
+			add("match.max_width", "不能小于 min_width")
+		}
+		if m.MaxHeight > 0 && m.MinHeight > m.MaxHeight {
+			add("match.max_height", "不能小于 min_height")
+		}
+	}
+	return errs
+}
+
+// ValidateSettings 校验常规设置项（不含规则），供设置窗口保存前检查
+func (c AppConfig) ValidateSettings() ValidationError {
+	var errs ValidationError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	root := strings.TrimSpace(c.StorageRoot)
+	if root == "" {
+		add("storage_root", "不能为空")
+	} else if !filepath.IsAbs(root) {
+		add("storage_root", "必须为绝对路径")
+	} else if dir, ok := existingAncestor(root); !ok {
+		// 缺失的目录会在首次截图时创建，但连驱动器都不存在时路径必然有误
+		add("storage_root", "%s 不存在", dir)
+	} else if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		add("storage_root", "%s 不是文件夹", dir)
+	}
+	if c.ScreenshotIntervalSec < 1 {
+		add("screenshot_interval_sec", "不能小于 1")
+	}
+	if c.DedupeThreshold < 1 || c.DedupeThreshold > 100 {
+		add("dedupe_threshold", "必须在 1 到 100 之间")
+	}
+	if c.IdlePauseMinutes < 1 {
+		add("idle_pause_minutes", "不能小于 1")
+	}
+	if c.BlankTolerance < 0 || c.BlankTolerance > 64 {
+		add("blank_tolerance", "必须在 0 到 64 之间")
+	}
+	switch c.BlankAction {
+	case BlankActionSkip, BlankActionRetry:
+	default:
+		add("blank_action", "必须为 %q 或 %q", BlankActionSkip, BlankActionRetry)
+	}
+	if b := strings.ToLower(strings.TrimSpace(c.CaptureBackend)); b != "" {
+		names := sys_utils.CaptureBackendNames()
+		found := false
+		for _, n := range names {
+			found = found || n == b
+		}
+		if !found {
+			add("capture_backend", "未知的截图后端 %q，可用: %s", c.CaptureBackend, strings.Join(names, ", "))
+		}
+	}
+	return errs
+}
+
+// existingAncestor 返回 path 自身或最近的已存在上级路径；一直到卷根都不存在时 ok 为 false，返回卷根
+func existingAncestor(path string) (string, bool) {
+	p := filepath.Clean(path)
+	for {
+		if _, err := os.Stat(p); err == nil {
+			return p, true
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return p, false
+		}
+		p = parent
+	}
+}
+
+// Validate 校验整个配置，返回按字段路径排列的错误；无错误时返回 nil
+func (c AppConfig) Validate() ValidationError {
+	errs := c.ValidateSettings()
+	for i, r := range c.Rules {
+		errs = append(errs, prefixed(fmt.Sprintf("rules[%d]", i), ValidateRule(r))...)
+	}
+	for i, r := range c.ScreenRules {
+		errs = append(errs, prefixed(fmt.Sprintf("screen_rules[%d]", i), validateScreenRule(r))...)
+	}
+	seen := map[string]bool{}
+	for i, g := range c.RuleGroups {
+		field := fmt.Sprintf("rule_groups[%d]", i)
+		name := strings.TrimSpace(g.Name)
+		switch {
+		case name == "":
+			errs = append(errs, FieldError{Field: field + ".name", Message: "不能为空"})
+		case seen[name]:
+			errs = append(errs, FieldError{Field: field + ".name", Message: fmt.Sprintf("规则组 %q 重复", name)})
+		}
+		seen[name] = true
+		if g.Schedule != nil {
+			if err := g.Schedule.Validate(); err != nil {
+				errs = append(errs, FieldError{Field: field + ".schedule", Message: err.Error()})
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateScreenRule 校验单条屏幕规则
+func validateScreenRule(r ScreenRule) []FieldError {
+	var errs []FieldError
+	if strings.TrimSpace(r.Name) == "" {
+		errs = append(errs, FieldError{Field: "name", Message: "不能为空"})
+	}
+	if r.Monitor < 0 {
+		errs = append(errs, FieldError{Field: "monitor", Message: "不能小于 0"})
+	}
+	switch strings.ToLower(strings.TrimSpace(r.Target)) {
+	case TargetMonitor, TargetAllMonitors:
+	case TargetRect:
+		if r.Rect == nil || r.Rect.Width <= 0 || r.Rect.Height <= 0 {
+			errs = append(errs, FieldError{Field: "rect", Message: "截图目标为 rect 时宽度和高度必须大于 0"})
+		}
+	default:
+		errs = append(errs, FieldError{Field: "target", Message: fmt.Sprintf("必须为 %q、%q 或 %q", TargetMonitor, TargetAllMonitors, TargetRect)})
+	}
+	return errs
+}
+
+// Lines 逐行列出字段错误，便于在界面与命令行中展示
+func (e ValidationError) Lines() string {
+	lines := make([]string, 0, len(e))
+	for _, fe := range e {
+		lines = append(lines, fe.Error())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRuleFolderTemplate(t *testing.T) {
+	tests := []struct {
+		storage, template string
+		want              []string
+	}{
+		{`(?<project>\w+) - (?<branch>\w+)`, "{project}/{branch|main}", nil},
+		{`(?<project>\w+) - (?<branch>\w+)`, "{projcet}/{branch}", []string{"{projcet}"}},
+		{"", "{title}", nil},
+		{"", "{project}", []string{"{project}"}},
+		// 存储规则无效时只报告正则错误
+		{`(?<project`, "{projcet}", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, fe := range ValidateRule(AppRule{Pattern: "x", StorageRule: tt.storage, FolderTemplate: tt.template}) {
+			if fe.Field == "folder_template" {
+				got = append(got, fe.Message)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("storage %q template %q: errors %q, want %d", tt.storage, tt.template, got, len(tt.want))
+			continue
+		}
+		for i := range got {
+			if !strings.Contains(got[i], tt.want[i]) {
+				t.Errorf("storage %q template %q: error %q does not mention %s", tt.storage, tt.template, got[i], tt.want[i])
+			}
+		}
+	}
+}
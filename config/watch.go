@@ -0,0 +1,174 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay 文件变化后等待的时间，合并编辑器保存时产生的多个事件
+const reloadDelay = 300 * time.Millisecond
+
+// markSynced 记录与磁盘一致的配置内容
+func (s *Store) markSynced(data []byte) {
+	s.syncedMu.Lock()
+	s.syncedHash = sha256.Sum256(data)
+	s.syncedMu.Unlock()
+}
+
+// isSynced 判断内容是否与最近一次读写的配置相同
+func (s *Store) isSynced(data []byte) bool {
+	s.syncedMu.Lock()
+	defer s.syncedMu.Unlock()
+	return s.syncedHash == sha256.Sum256(data)
+}
+
+// Watch 监听配置文件，在外部修改（手工编辑或部署工具写入）后重新加载；本程序自身的写入被忽略
+// 其它进程（如命令行 profile use）切换配置方案时同样切换到新方案
+// onReload 在后台协程中调用，传入重新加载前后的配置以及加载错误（含校验错误）；
+// 文件无法解析时保持当前配置不变，old 与 cur 相同。返回的 stop 用于停止监听
+func (s *Store) Watch(onReload func(old, cur AppConfig, err error)) (stop func(), err error) {
+	if s.dir == "" {
+		return nil, errors.New("memory store has no config file to watch")
+	}
+	base := s.dir
+	profiles := filepath.Join(base, profilesDirName)
+	_ = os.MkdirAll(profiles, 0755)
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// 监听所在目录：编辑器与本程序都以“写临时文件再重命名”的方式保存，直接监听文件会在替换后失效
+	for _, dir := range []string{base, profiles} {
+		if err := w.Add(dir); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+	statePath := filepath.Join(base, profileStateName)
+	done := make(chan struct{})
+	fire := make(chan struct{}, 1)
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				name := filepath.Clean(ev.Name)
+				if !ev.Has(fsnotify.Write|fsnotify.Create) || !(strings.EqualFold(name, s.configPath()) || strings.EqualFold(name, statePath)) {
+					continue
+				}
+				if timer == nil {
+					timer = time.AfterFunc(reloadDelay, func() {
+						select {
+						case fire <- struct{}{}:
+						default:
+						}
+					})
+				} else {
+					timer.Reset(reloadDelay)
+				}
+			case <-fire:
+				if name := s.readProfileState(); name != s.ActiveProfile() {
+					old := s.Snapshot()
+					err := s.SwitchProfile(name)
+					onReload(old, s.Snapshot(), err)
+					continue
+				}
+				s.reloadIfChanged(onReload)
+			case _, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+			case <-done:
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			w.Close()
+		})
+	}, nil
+}
+
+// reloadIfChanged 配置文件内容与最近一次读写不同时重新加载
+func (s *Store) reloadIfChanged(onReload func(old, cur AppConfig, err error)) {
+	data, err := os.ReadFile(s.configPath())
+	if err != nil || s.isSynced(data) {
+		return
+	}
+	old := s.Snapshot()
+	// 外部写入可能尚未完成，此时不从备份恢复，等待下一次变化
+	if !validConfigData(s.configPath(), data) {
+		onReload(old, old, errors.New("配置文件无法解析，保持当前配置"))
+		return
+	}
+	err = s.Load()
+	onReload(old, s.Snapshot(), err)
+}
+
+// DiffConfig 按顶层字段列出两份配置的差异，形如 "dedupe_threshold: 100 -> 80"；
+// 规则等较长的值只给出条目数变化
+func DiffConfig(old, cur AppConfig) []string {
+	fields := func(c AppConfig) map[string]json.RawMessage {
+		m := map[string]json.RawMessage{}
+		if b, err := json.Marshal(c); err == nil {
+			_ = json.Unmarshal(b, &m)
+		}
+		return m
+	}
+	a, b := fields(old), fields(cur)
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var out []string
+	for _, k := range keys {
+		x, y := string(a[k]), string(b[k])
+		if x == y {
+			continue
+		}
+		if len(x) <= 80 && len(y) <= 80 {
+			out = append(out, fmt.Sprintf("%s: %s -> %s", k, orNone(x), orNone(y)))
+			continue
+		}
+		var xs, ys []json.RawMessage
+		if json.Unmarshal(a[k], &xs) == nil && json.Unmarshal(b[k], &ys) == nil {
+			out = append(out, fmt.Sprintf("%s: changed (%d -> %d items)", k, len(xs), len(ys)))
+			continue
+		}
+		out = append(out, k+": changed")
+	}
+	return out
+}
+
+// orNone 缺失的字段显示为 none
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -3,6 +3,7 @@ package constants
 // 通用常量
 const (
 	TextUnknownName        = "未知名称"
+	TextScreenFolder       = "屏幕"
 	TextOpenPicturesFolder = "图片文件夹"
 	TextOpenConfigFolder   = "配置文件夹"
 	TextTrayShow           = "显示"
@@ -11,34 +12,179 @@ const (
 
 // GUI 文本常量
 const (
-	TextAppTitle           = "CronShot"
-	TextOpenAutoShot       = "开启自动截图"
-	TextCloseAutoShot      = "关闭自动截图"
-	TextSettings           = "设置"
-	TextSettingsTitle      = "设置"
-	TextStorageRootTitle   = "存储路径"
-	TextIntervalTitle      = "截图周期（秒）"
-	TextDedupeTitle        = "相同图片去重"
-	TextDedupeThreshold    = "重复度阈值（1-100）"
-	TextChoose             = "选择"
-	TextResetDefault       = "恢复默认"
-	TextSave               = "保存"
-	TextCancel             = "取消"
-	TextAutoStartTitle     = "开机自启动"
-	TextAutoCaptureTitle   = "自动开启截图"
-	TextSilentStartTitle   = "静默启动"
-	TextAbout              = "关于"
-	TextRulesHeader        = "已添加规则:"
-	TextRuleLabel          = "规则:"
-	TextAdd                = "新增"
-	TextActivate           = "激活"
-	TextDeactivate         = "禁用"
-	TextDelete             = "删除"
-	TextConfig             = "配置"
-	TextWindowStatusHeader = "进程窗口状态:"
-	TextStorageRuleTitle   = "存储文件夹规则"
-	TextFixedFolderTitle   = "固定文件夹"
-	PlaceholderStorageRule = "未配置时，默认以窗口名称存储。"
-	PlaceholderFixedFolder = "固定文件夹（留空则不启用）"
-	TextCopiedBubble       = "已复制"
+	TextAppTitle              = "CronShot"
+	TextOpenAutoShot          = "开启自动截图"
+	TextCloseAutoShot         = "关闭自动截图"
+	TextSettings              = "设置"
+	TextSettingsTitle         = "设置"
+	TextStorageRootTitle      = "存储路径"
+	TextIntervalTitle         = "截图周期（秒）"
+	TextDedupeTitle           = "相同图片去重"
+	TextDedupeThreshold       = "重复度阈值（1-100）"
+	TextChoose                = "选择"
+	TextResetDefault          = "恢复默认"
+	TextSave                  = "保存"
+	TextCancel                = "取消"
+	TextAutoStartTitle        = "开机自启动"
+	TextAutoCaptureTitle      = "自动开启截图"
+	TextSilentStartTitle      = "静默启动"
+	TextAbout                 = "关于"
+	TextRulesHeader           = "已添加规则:"
+	TextRuleLabel             = "规则:"
+	TextAdd                   = "新增"
+	TextActivate              = "激活"
+	TextDeactivate            = "禁用"
+	TextDelete                = "删除"
+	TextConfig                = "配置"
+	TextWindowStatusHeader    = "进程窗口状态:"
+	TextStorageRuleTitle      = "存储文件夹规则"
+	TextFixedFolderTitle      = "固定文件夹"
+	PlaceholderStorageRule    = "未配置时，默认以窗口名称存储。"
+	PlaceholderFixedFolder    = "固定文件夹（留空则不启用）"
+	TextFolderTemplateTitle   = "文件夹模板"
+	PlaceholderFolderTemplate = "如 {project}/{branch|main}，留空取第一个捕获组"
+	TextCopiedBubble          = "已复制"
+	TextIdlePauseTitle        = "空闲时暂停截图"
+	TextIdleMinutesTitle      = "空闲判定时长（分钟）"
+	TextPauseOnLockTitle      = "锁屏或屏保时暂停"
+)
+
+// 空白帧检测文本常量
+const (
+	TextBlankDetectTitle    = "空白帧检测"
+	TextBlankToleranceTitle = "空白判定容差（0-64）"
+	TextBlankActionSkip     = "跳过并记录日志"
+	TextBlankActionRetry    = "换用其它截图方式重试"
+)
+
+// 截图目标文本常量
+const (
+	TextCaptureTargetTitle  = "截图目标"
+	TextTargetWindow        = "窗口"
+	TextTargetMonitor       = "显示器"
+	TextTargetAllMonitors   = "全部显示器"
+	TextTargetRect          = "固定区域"
+	TextMonitorFollowWindow = "窗口所在显示器"
+	TextMonitorPrimary      = "（主）"
+	TextCaptureRectError    = "区域格式错误"
+	PlaceholderCaptureRect  = "区域：x,y,宽,高"
+)
+
+// 匹配条件文本常量
+const (
+	TextMatchTitle          = "附加匹配条件"
+	TextMatchAll            = "满足全部条件"
+	TextMatchAny            = "满足任一条件"
+	TextMonitorAny          = "任意显示器"
+	TextMatchSizeError      = "尺寸格式错误"
+	TextMatchConditionError = "匹配条件错误"
+	TextRegexError          = "正则表达式错误"
+	PlaceholderMatchClass   = "窗口类名（正则）"
+	PlaceholderMatchExe     = "可执行文件完整路径（正则）"
+	PlaceholderMatchCmd     = "命令行参数（正则）"
+	PlaceholderMatchParent  = "父进程名（正则）"
+	PlaceholderMatchMin     = "最小尺寸：宽x高"
+	PlaceholderMatchMax     = "最大尺寸：宽x高"
+)
+
+// 配置方案文本常量
+const (
+	TextProfiles           = "配置方案"
+	TextProfileCreate      = "新建配置方案…"
+	TextProfileName        = "方案名称"
+	TextProfileCreateHint  = "新方案复制当前方案的全部设置与规则"
+	TextProfileSwitchError = "切换配置方案失败"
+	TextProfileCreateError = "新建配置方案失败"
+)
+
+// 配置校验文本常量
+const (
+	TextConfigInvalid   = "配置有误"
+	TextConfigLoadError = "加载配置时发现问题"
+	TextConfigSaveError = "保存配置失败"
+	TextAutostartError  = "设置开机自启失败"
+	TextIntegerRequired = "must be an integer"
+)
+
+// 规则列表批量操作文本常量
+const (
+	TextSelectAll = "全选"
+	TextMoveUp    = "上移"
+	TextMoveDown  = "下移"
+	TextDuplicate = "复制"
+	TextUndo      = "撤销"
+)
+
+// 规则语义文本常量
+const (
+	TextRuleExcludeTitle  = "排除规则（命中的窗口不截图）"
+	TextRuleContinueTitle = "命中后继续匹配后续规则"
+	TextRulePriorityTitle = "优先级（越大越先匹配）"
+	TextRulePriorityError = "优先级必须为整数"
+	TextRuleExcludeTag    = "[排除] "
+	TextRuleContinueTag   = "[继续] "
+)
+
+// 规则测试文本常量
+const (
+	TextRuleTester           = "规则测试"
+	TextRuleTesterLive       = "当前进程窗口"
+	TextRuleTesterSamples    = "示例标题"
+	TextRuleTesterResult     = "测试结果"
+	TextRuleTesterRefresh    = "刷新"
+	TextRuleTesterNoMatch    = "未命中任何规则"
+	TextRuleTesterExcluded   = "被排除规则终止："
+	TextRuleTesterRule       = "命中规则："
+	TextRuleTesterGroups     = "匹配捕获组："
+	TextRuleTesterStorage    = "存储规则捕获组："
+	TextRuleTesterFolder     = "解析文件夹："
+	TextRuleTesterFixed      = "固定文件夹："
+	TextRuleTesterPath       = "保存路径："
+	TextRuleTesterConditions = "（附加匹配条件未参与测试）"
+	PlaceholderSampleTitle   = "输入示例窗口标题后回车"
+)
+
+// 规则包文本常量
+const (
+	TextRulePack            = "规则导入/导出"
+	TextRulePackFile        = "规则包文件（.json / .yaml）"
+	TextRulePackBrowse      = "浏览"
+	TextRulePackImport      = "导入"
+	TextRulePackExport      = "导出"
+	TextRulePackStrategy    = "导入方式"
+	TextMergeAppend         = "追加到现有规则之后"
+	TextMergeReplace        = "替换全部现有规则"
+	TextMergeSkipDuplicates = "追加并跳过重复规则"
+	TextRulePackImportError = "导入失败"
+	TextRulePackExportError = "导出失败"
+	TextRulePackImported    = "已导入 %d 条规则"
+	TextRulePackExported    = "已导出 %d 条规则"
+	PlaceholderRulePackPath = "文件路径"
+)
+
+// 规则组文本常量
+const (
+	TextRuleGroups        = "规则组"
+	TextRuleGroupTitle    = "所属规则组（留空表示不分组）"
+	TextRuleGroupsHeader  = "规则组列表"
+	TextRuleGroupSchedule = "时间表"
+	TextScheduleEnabled   = "按时间表自动启用"
+	TextScheduleStart     = "开始时间（HH:MM）"
+	TextScheduleEnd       = "结束时间（HH:MM）"
+	TextScheduleDays      = "生效日（不选表示每天）"
+	TextScheduleError     = "时间表格式错误"
+	TextRuleGroupExists   = "规则组已存在"
+	TextRuleGroupActive   = "（生效中）"
+	PlaceholderRuleGroup  = "新规则组名称"
+)
+
+// 星期显示文本（0=周日）
+var TextWeekdays = []string{"日", "一", "二", "三", "四", "五", "六"}
+
+// 暂停状态文本常量
+const (
+	TextPausedPrefix           = "已暂停："
+	TextPauseReasonIdle        = "用户空闲"
+	TextPauseReasonLocked      = "会话已锁定"
+	TextPauseReasonScreenSaver = "屏保运行中"
 )
@@ -0,0 +1,149 @@
+package gui
+
+import (
+	"cron-shot/config"
+	"cron-shot/constants"
+	"cron-shot/sys_utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// captureTargetForm 截图目标选择表单（窗口/显示器/全部显示器/固定区域）
+type captureTargetForm struct {
+	Container     *fyne.Container
+	selectTarget  *widget.Select
+	selectMonitor *widget.Select
+	entryRect     *widget.Entry
+}
+
+// 目标类型与显示文本的对应关系（保持选项顺序）
+var targetOptions = []struct{ Kind, Text string }{
+	{config.TargetWindow, constants.TextTargetWindow},
+	{config.TargetMonitor, constants.TextTargetMonitor},
+	{config.TargetAllMonitors, constants.TextTargetAllMonitors},
+	{config.TargetRect, constants.TextTargetRect},
+}
+
+// newCaptureTargetForm 创建截图目标表单并填充当前值
+func newCaptureTargetForm(target string, monitor int, rect *config.CaptureRect) *captureTargetForm {
+	f := &captureTargetForm{}
+
+	// 显示器选项：第 0 项为“窗口所在显示器”，其余按枚举顺序编号
+	monitorOpts := monitorOptions(constants.TextMonitorFollowWindow)
+	f.selectMonitor = widget.NewSelect(monitorOpts, nil)
+	if monitor >= 0 && monitor < len(monitorOpts) {
+		f.selectMonitor.SetSelectedIndex(monitor)
+	} else {
+		f.selectMonitor.SetSelectedIndex(0)
+	}
+
+	f.entryRect = widget.NewEntry()
+	f.entryRect.PlaceHolder = constants.PlaceholderCaptureRect
+	if rect != nil {
+		f.entryRect.SetText(fmt.Sprintf("%d,%d,%d,%d", rect.X, rect.Y, rect.Width, rect.Height))
+	}
+
+	var texts []string
+	for _, o := range targetOptions {
+		texts = append(texts, o.Text)
+	}
+	f.selectTarget = widget.NewSelect(texts, func(string) { f.updateVisibility() })
+	f.selectTarget.SetSelectedIndex(0)
+	for i, o := range targetOptions {
+		if o.Kind == target {
+			f.selectTarget.SetSelectedIndex(i)
+		}
+	}
+
+	f.Container = container.NewVBox(
+		widget.NewLabel(constants.TextCaptureTargetTitle),
+		f.selectTarget,
+		f.selectMonitor,
+		f.entryRect,
+	)
+	f.updateVisibility()
+	return f
+}
+
+// monitorOptions 返回显示器下拉选项：第 0 项为 first，其余按枚举顺序编号（从 1 开始）
+func monitorOptions(first string) []string {
+	opts := []string{first}
+	if mons, err := sys_utils.DefaultCaptureBackend().Monitors(); err == nil {
+		for _, m := range mons {
+			label := fmt.Sprintf("%s %d (%dx%d)", constants.TextTargetMonitor, m.Index+1, m.Bounds.Dx(), m.Bounds.Dy())
+			if m.Primary {
+				label += " " + constants.TextMonitorPrimary
+			}
+			opts = append(opts, label)
+		}
+	}
+	return opts
+}
+
+// kind 返回当前选择的目标类型
+func (f *captureTargetForm) kind() string {
+	i := f.selectTarget.SelectedIndex()
+	if i < 0 || i >= len(targetOptions) {
+		return config.TargetWindow
+	}
+	return targetOptions[i].Kind
+}
+
+// updateVisibility 仅显示与当前目标类型相关的输入项
+func (f *captureTargetForm) updateVisibility() {
+	if f.selectMonitor == nil || f.entryRect == nil {
+		return
+	}
+	f.selectMonitor.Hide()
+	f.entryRect.Hide()
+	switch f.kind() {
+	case config.TargetMonitor:
+		f.selectMonitor.Show()
+	case config.TargetRect:
+		f.entryRect.Show()
+	}
+}
+
+// Values 返回表单值；窗口目标以空字符串保存以保持旧配置兼容
+func (f *captureTargetForm) Values() (string, int, *config.CaptureRect, error) {
+	kind := f.kind()
+	switch kind {
+	case config.TargetWindow:
+		return "", 0, nil, nil
+	case config.TargetMonitor:
+		return kind, f.selectMonitor.SelectedIndex(), nil, nil
+	case config.TargetRect:
+		r, err := parseCaptureRect(f.entryRect.Text)
+		if err != nil {
+			return "", 0, nil, err
+		}
+		return kind, 0, r, nil
+	}
+	return kind, 0, nil, nil
+}
+
+// parseCaptureRect 解析 “x,y,宽,高” 格式的区域文本
+func parseCaptureRect(s string) (*config.CaptureRect, error) {
+	parts := strings.Split(strings.TrimSpace(s), ",")
+	if len(parts) != 4 {
+		return nil, errors.New(constants.PlaceholderCaptureRect)
+	}
+	var v [4]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, err
+		}
+		v[i] = n
+	}
+	if v[2] <= 0 || v[3] <= 0 {
+		return nil, errors.New(constants.PlaceholderCaptureRect)
+	}
+	return &config.CaptureRect{X: v[0], Y: v[1], Width: v[2], Height: v[3]}, nil
+}
@@ -1,6 +1,9 @@
 package gui
 
 import (
+	"cron-shot/config"
+	"cron-shot/constants"
+	"fmt"
 	"image/color"
 
 	"fyne.io/fyne/v2"
@@ -12,10 +15,56 @@ import (
 
 // WindowRule 定义窗口匹配规则
 type WindowRule struct {
-	Pattern     string // 文本内容
-	Enabled     bool   // 是否激活
-	StorageRule string // 存储文件夹规则（固定文本或带捕获组的正则）
-	FixedFolder string // 固定文件夹（若不为空，则优先在此文件夹下存储）
+	Pattern        string              // 文本内容
+	Enabled        bool                // 是否激活
+	StorageRule    string              // 存储文件夹规则（固定文本或带捕获组的正则）
+	FixedFolder    string              // 固定文件夹（若不为空，则优先在此文件夹下存储）
+	FolderTemplate string              // 文件夹模板（引用捕获组组合多级文件夹）
+	Target         string              // 截图目标（窗口/显示器/全部显示器/固定区域）
+	Monitor        int                 // 显示器序号（从 1 开始，0 表示窗口所在显示器）
+	Rect           *config.CaptureRect // 固定区域
+	Match          *config.WindowMatch // 标题之外的匹配条件
+	Exclude        bool                // 排除规则
+	Priority       int                 // 优先级（越大越先匹配）
+	Continue       bool                // 命中后继续匹配后续规则
+	Group          string              // 所属规则组
+	selected       bool                // 列表中是否勾选（仅界面使用，不持久化）
+}
+
+// toConfigRules 将界面规则转换为配置规则
+func toConfigRules(rules []WindowRule) []config.AppRule {
+	var cfgRules []config.AppRule
+	for _, r := range rules {
+		cfgRules = append(cfgRules, config.AppRule{Pattern: r.Pattern, Enabled: r.Enabled, StorageRule: r.StorageRule, FixedFolder: r.FixedFolder, FolderTemplate: r.FolderTemplate, Target: r.Target, Monitor: r.Monitor, Rect: r.Rect, Match: r.Match, Exclude: r.Exclude, Priority: r.Priority, Continue: r.Continue, Group: r.Group})
+	}
+	return cfgRules
+}
+
+// fromConfigRules 将配置规则转换为界面规则
+func fromConfigRules(cfg []config.AppRule) []WindowRule {
+	var rules []WindowRule
+	for _, r := range cfg {
+		rules = append(rules, WindowRule{Pattern: r.Pattern, Enabled: r.Enabled, StorageRule: r.StorageRule, FixedFolder: r.FixedFolder, FolderTemplate: r.FolderTemplate, Target: r.Target, Monitor: r.Monitor, Rect: r.Rect, Match: r.Match, Exclude: r.Exclude, Priority: r.Priority, Continue: r.Continue, Group: r.Group})
+	}
+	return rules
+}
+
+// ruleLabel 返回规则在列表中的显示文本：排除、优先级与继续匹配以前缀标注
+func ruleLabel(r WindowRule) string {
+	prefix := ""
+	if r.Exclude {
+		prefix += constants.TextRuleExcludeTag
+	}
+	if r.Priority != 0 {
+		prefix += fmt.Sprintf("[P%d] ", r.Priority)
+	}
+	if r.Continue {
+		prefix += constants.TextRuleContinueTag
+	}
+	if r.Group != "" {
+		prefix += "[" + r.Group + "] "
+	}
+	return prefix + r.Pattern
 }
 
 var AppCanvas fyne.Canvas
@@ -1,16 +1,27 @@
 package gui
 
 import (
+	"cron-shot/config"
+	"errors"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 )
 
-// 辅助函数：显示错误窗口
+// 辅助函数：显示错误窗口；配置校验错误逐行列出各字段
 func showError(app fyne.App, title string, err error) {
-	dialog := widget.NewLabel(title + ": " + err.Error())
+	text := title + ": " + err.Error()
+	size := fyne.NewSize(300, 100)
+	var verr config.ValidationError
+	if errors.As(err, &verr) {
+		text = title + ":\n" + verr.Lines()
+		size = fyne.NewSize(480, 160)
+	}
+	dialog := widget.NewLabel(text)
+	dialog.Wrapping = fyne.TextWrapWord
 	w := app.NewWindow("错误")
-	w.SetContent(container.NewPadded(dialog))
-	w.Resize(fyne.NewSize(300, 100))
+	w.SetContent(container.NewVScroll(container.NewPadded(dialog)))
+	w.Resize(size)
 	w.Show()
 }
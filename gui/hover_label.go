@@ -16,11 +16,13 @@ import (
 )
 
 // HoverLabel 是一个支持鼠标悬停显示 Tooltip 的 Label
+// OnDoubleTapped 不为空时在双击时调用
 type HoverLabel struct {
 	ttwidget.ToolTipWidget
-	label       *widget.Label
-	bg          *canvas.Rectangle
-	highlighted bool
+	label          *widget.Label
+	bg             *canvas.Rectangle
+	highlighted    bool
+	OnDoubleTapped func()
 }
 
 // NewHoverLabel 创建一个新的 HoverLabel
@@ -56,6 +58,12 @@ func (l *HoverLabel) SetHighlighted(h bool) {
 	l.Refresh()
 }
 
+func (l *HoverLabel) DoubleTapped(*fyne.PointEvent) {
+	if l.OnDoubleTapped != nil {
+		l.OnDoubleTapped()
+	}
+}
+
 func (l *HoverLabel) TappedSecondary(ev *fyne.PointEvent) {
 	fyne.CurrentApp().Clipboard().SetContent(l.label.Text)
 	l.showCopiedBubble(ev.Position)
@@ -1,10 +1,9 @@
 package gui
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -18,40 +17,69 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	fynetooltip "github.com/dweymouth/fyne-tooltip"
 )
 
 // Run 启动应用程序主界面与业务逻辑
-func Run() {
+// store 为已加载的配置，界面各模块均通过它读写配置
+func Run(store *config.Store) {
 	defer logging.RecoverPanic("gui.Run")
+	// 在创建任何窗口之前启用按显示器 DPI 感知，保证窗口坐标与截图为物理像素
+	dpiErr := sys_utils.EnableDPIAwareness()
 	myApp := app.New()
 	myApp.Settings().SetTheme(&customTheme{})
 	myApp.SetIcon(platformwin.GetTrayIconResource())
 	myWindow := myApp.NewWindow(constants.TextAppTitle)
 	AppCanvas = myWindow.Canvas()
-	config.Init()
-	cfgDir, _ := os.UserConfigDir()
-	baseCfg := filepath.Join(cfgDir, "CronShot")
-	_ = logging.Init(baseCfg)
-	logging.Info("config loaded")
+	myWindow.SetTitle(windowTitle(store))
+	// 日志与配置位于同一目录（--config-dir、CRONSHOT_HOME 或便携模式）
+	_ = logging.Init(store.Dir())
+	logging.Info("config loaded from " + store.Dir() + " (" + config.BaseDirSource() + ")")
+	loadErr := store.LoadError()
+	if loadErr != nil {
+		logging.Error("load config: " + loadErr.Error())
+	}
+	store.SetSaveErrorHandler(func(err error) {
+		logging.Error("save config: " + err.Error())
+		fyne.Do(func() { showError(myApp, constants.TextConfigSaveError, err) })
+	})
+	if dpiErr != nil {
+		logging.Info("dpi awareness not changed: " + dpiErr.Error())
+	}
+	if err := sys_utils.SelectCaptureBackend(store.Snapshot().CaptureBackend); err != nil {
+		logging.Error("select capture backend failed: " + err.Error())
+	}
 
-	platformwin.InitAutostartRegistration()
+	platformwin.InitAutostartRegistration(store)
 
 	// 初始化各个模块
-	rulesUI := NewRulesUI(myApp)
+	ruleSet, stopRuleSet := appctrl.RuleSetSource(store)
+	defer stopRuleSet()
+	rulesUI := NewRulesUI(myApp, store)
 	windowStatusUI := NewWindowStatusUI()
-	windowStatusUI.RulesProvider = func() []WindowRule { return rulesUI.Rules }
+	windowStatusUI.RuleSet = ruleSet
 	processController := appctrl.NewProcessWindowController()
 	processController.OnWindowsUpdated = func(w []string) { windowStatusUI.UpdateWindows(w) }
+	// refreshGroups 将规则组同步到托盘菜单；托盘中切换后同样刷新窗口高亮
+	var refreshGroups func()
+	refreshGroups = func() {
+		var items []platformwin.TrayGroup
+		for _, g := range store.Snapshot().RuleGroups {
+			items = append(items, platformwin.TrayGroup{Name: g.Name, Enabled: g.Enabled})
+		}
+		platformwin.SetTrayGroups(items, func(name string, enabled bool) {
+			// 菜单与窗口高亮经规则变更通知刷新
+			if err := store.SetRuleGroupEnabled(name, enabled); err != nil {
+				logging.Error("toggle rule group failed: " + err.Error())
+			}
+		})
+	}
 	rulesUI.OnRulesChanged = func() {
+		// 规则或规则组在配置中变化后（来自界面、托盘、命令行或外部修改），刷新窗口高亮与规则组菜单
 		windowStatusUI.UpdateWindows(windowStatusUI.Windows)
-		// 持久化规则
-		var cfgRules []config.AppRule
-		for _, r := range rulesUI.Rules {
-			cfgRules = append(cfgRules, config.AppRule{Pattern: r.Pattern, Enabled: r.Enabled, StorageRule: r.StorageRule, FixedFolder: r.FixedFolder})
-		}
-		config.SetRules(cfgRules)
+		refreshGroups()
 	}
 
 	var currentProcess string
@@ -60,18 +88,19 @@ func Run() {
 		// 切换监控的进程，这会立即更新一次窗口列表，并启动定时轮询
 		processController.SetProcess(selectedProcess)
 		currentProcess = selectedProcess
-		config.SetCurrentProcess(selectedProcess)
+		_ = store.Update(func(c *config.AppConfig) error {
+			c.CurrentProcess = selectedProcess
+			return nil
+		})
 	})
 
 	// 从配置加载规则与进程
-	if cfg := config.GetRules(); len(cfg) > 0 {
-		rulesUI.Rules = nil
-		for _, r := range cfg {
-			rulesUI.Rules = append(rulesUI.Rules, WindowRule{Pattern: r.Pattern, Enabled: r.Enabled, StorageRule: r.StorageRule, FixedFolder: r.FixedFolder})
-		}
+	initial := store.Snapshot()
+	if len(initial.Rules) > 0 {
+		rulesUI.Rules = fromConfigRules(initial.Rules)
 		rulesUI.RuleList.Refresh()
 	}
-	if p := config.GetCurrentProcess(); strings.TrimSpace(p) != "" {
+	if p := initial.CurrentProcess; strings.TrimSpace(p) != "" {
 		currentProcess = p
 		processController.SetProcess(p)
 		processUI.EntryProcessLocked.SetText(p)
@@ -86,9 +115,42 @@ func Run() {
 
 	autoEnabled := false
 	autoCtrl := appctrl.NewAutoCaptureController(
+		store,
 		func() string { return currentProcess },
-		func() []config.AppRule { return config.GetRules() },
+		ruleSet,
 	)
+	// 空闲/锁屏暂停时在托盘提示中显示原因，恢复后还原
+	autoCtrl.OnPauseChanged = func(paused bool, reason string) {
+		if paused {
+			platformwin.SetTrayStatus(constants.TextPausedPrefix + reason)
+		} else {
+			platformwin.SetTrayStatus("")
+		}
+	}
+
+	// 订阅配置变化：截图周期由 autoCtrl 自行订阅，其余设置在每次截图时读取，均无需重启自动截图
+	config.OnChange(store, config.ChangeProcess, func(config.Change, config.AppConfig, config.AppConfig) {
+		fyne.Do(func() {
+			p := store.Snapshot().CurrentProcess
+			if p == currentProcess {
+				return
+			}
+			currentProcess = p
+			processController.SetProcess(p)
+			processUI.EntryProcessLocked.SetText(p)
+		})
+	})
+	config.OnChange(store, config.ChangeCaptureBackend, func(_ config.Change, _, cur config.AppConfig) {
+		if err := sys_utils.SelectCaptureBackend(cur.CaptureBackend); err != nil {
+			logging.Error("select capture backend failed: " + err.Error())
+			return
+		}
+		autoCtrl.SetBackend(sys_utils.DefaultCaptureBackend())
+	})
+	platformwin.WatchAutostart(store, func(err error) {
+		logging.Error("autostart: " + err.Error())
+		fyne.Do(func() { showError(myApp, constants.TextAutostartError, err) })
+	})
 	autoBtn := widget.NewButton(constants.TextOpenAutoShot, nil)
 	autoBtn.Importance = widget.MediumImportance
 	autoBtn.OnTapped = func() {
@@ -106,19 +168,13 @@ func Run() {
 		}
 	}
 	settingsBtn := widget.NewButton(constants.TextSettings, func() {
-		onSettingsButtonTapped(myApp, &autoEnabled, nil, autoBtn, &[]bool{config.GetDedupeEnabled()}[0], &currentProcess, rulesUI, windowStatusUI)
-		if autoEnabled {
-			autoCtrl.Stop()
-			autoCtrl.Start()
-		}
+		onSettingsButtonTapped(myApp, store)
 	})
 	openPicturesBtn := widget.NewButton(constants.TextOpenPicturesFolder, func() {
-		_ = sys_utils.OpenFolder(config.GetStorageRoot())
+		_ = sys_utils.OpenFolder(store.Snapshot().StorageRoot)
 	})
 	openConfigBtn := widget.NewButton(constants.TextOpenConfigFolder, func() {
-		cfgDir, _ := os.UserConfigDir()
-		base := filepath.Join(cfgDir, constants.TextAppTitle)
-		_ = sys_utils.OpenFolder(base)
+		_ = sys_utils.OpenFolder(store.Dir())
 	})
 	buttonsRow := container.NewGridWithColumns(1, autoBtn)
 	bottomRow := container.NewBorder(nil, nil, nil, nil, buttonsRow)
@@ -138,8 +194,17 @@ func Run() {
 		w.SetOnClosed(func() { fynetooltip.DestroyWindowToolTipLayer(w.Canvas()) })
 		w.Show()
 	})
-	actionsTop := container.NewGridWithColumns(2, openPicturesBtn, openConfigBtn)
-	actionsBottom := container.NewGridWithColumns(2, settingsBtn, aboutBtn)
+	rulePackBtn := widget.NewButton(constants.TextRulePack, func() {
+		showRulePack(myApp, store)
+	})
+	actionsTop := container.NewGridWithColumns(3, openPicturesBtn, openConfigBtn, rulePackBtn)
+	groupsBtn := widget.NewButton(constants.TextRuleGroups, func() {
+		showRuleGroups(myApp, store)
+	})
+	testerBtn := widget.NewButton(constants.TextRuleTester, func() {
+		showRuleTester(store, ruleSet, currentProcess, processController.Windows)
+	})
+	actionsBottom := container.NewGridWithColumns(4, settingsBtn, groupsBtn, testerBtn, aboutBtn)
 	actionsRow := container.NewVBox(actionsTop, actionsBottom)
 	centerContent = container.NewVBox(
 		rulesUI.Container,
@@ -159,8 +224,9 @@ func Run() {
 	myWindow.SetContent(wrapped)
 	myWindow.Resize(fyne.NewSize(600, 600))
 	platformwin.SetupSystemTray(myApp, myWindow, processController)
+	refreshGroups()
 	myWindow.SetCloseIntercept(myWindow.Hide)
-	if config.GetSilentStartEnabled() {
+	if initial.SilentStartEnabled {
 		platformwin.StartHideOnMinimize(myWindow)
 	}
 
@@ -170,32 +236,88 @@ func Run() {
 		fynetooltip.DestroyWindowToolTipLayer(myWindow.Canvas())
 	})
 
-	if config.GetAutoCaptureEnabled() && strings.TrimSpace(currentProcess) != "" && !autoEnabled {
+	if initial.AutoCaptureEnabled && strings.TrimSpace(currentProcess) != "" && !autoEnabled {
 		autoBtn.OnTapped()
 		autoBtn.Refresh()
 	}
-	if config.GetSilentStartEnabled() {
+	if loadErr != nil {
+		showError(myApp, constants.TextConfigLoadError, loadErr)
+	}
+
+	// applyConfig 在配置被重新加载（外部修改或切换配置方案）后记录差异并报告加载问题；
+	// 规则、进程、截图后端等由各自的变更订阅同步，这里只处理切换方案时的撤销记录、菜单与标题；需在界面线程调用
+	shownProfile := store.ActiveProfile()
+	var refreshProfiles func()
+	applyConfig := func(old, cur config.AppConfig, err error) {
+		for _, line := range config.DiffConfig(old, cur) {
+			logging.Info("config reloaded: " + line)
+		}
+		if err != nil {
+			logging.Error("reload config: " + err.Error())
+			showError(myApp, constants.TextConfigLoadError, err)
+		}
+		if p := store.ActiveProfile(); p != shownProfile {
+			logging.Info("profile switched: " + shownProfile + " -> " + p)
+			shownProfile = p
+			// 撤销不跨越配置方案
+			rulesUI.ResetRules(fromConfigRules(store.Snapshot().Rules))
+			refreshProfiles()
+			myWindow.SetTitle(windowTitle(store))
+		}
+	}
+	// switchProfile 切换配置方案并刷新界面
+	switchProfile := func(name string) {
+		old := store.Snapshot()
+		err := store.SwitchProfile(name)
+		if store.ActiveProfile() != name {
+			showError(myApp, constants.TextProfileSwitchError, err)
+			return
+		}
+		applyConfig(old, store.Snapshot(), err)
+	}
+	refreshProfiles = func() {
+		platformwin.SetTrayProfiles(store.ListProfiles(), store.ActiveProfile(), switchProfile, func() {
+			showProfileCreate(myApp, store, switchProfile)
+		})
+	}
+	refreshProfiles()
+
+	// 配置文件被外部修改、或其它进程切换了配置方案后实时生效
+	stopWatch, err := store.Watch(func(old, cur config.AppConfig, err error) {
+		fyne.Do(func() { applyConfig(old, cur, err) })
+	})
+	if err != nil {
+		logging.Error("watch config: " + err.Error())
+	} else {
+		defer stopWatch()
+	}
+	if initial.SilentStartEnabled {
 		myWindow.Hide()
 		myApp.Run()
 	} else {
 		myWindow.ShowAndRun()
 	}
+	// 写入尚未落盘的延迟保存
+	if err := store.Flush(); err != nil {
+		logging.Error("save config: " + err.Error())
+	}
 }
 
 // onSettingsButtonTapped 打开设置窗口并保存改动
-func onSettingsButtonTapped(_ fyne.App, autoEnabled *bool, autoStopChanPtr *chan struct{}, _ *widget.Button, dedupeEnabled *bool, currentProcess *string, rulesUI *RulesUI, windowStatusUI *WindowStatusUI) {
+func onSettingsButtonTapped(_ fyne.App, store config.Provider) {
 	w := NewSingletonWindow(constants.TextSettingsTitle)
+	cur := store.Snapshot()
 	entryRoot := widget.NewEntry()
-	entryRoot.SetText(config.GetStorageRoot())
+	entryRoot.SetText(cur.StorageRoot)
 	entryRootWrap := container.NewGridWrap(fyne.NewSize(420, entryRoot.MinSize().Height), entryRoot)
 	entryInterval := widget.NewEntry()
-	entryInterval.SetText(fmt.Sprintf("%d", config.GetScreenshotIntervalSec()))
+	entryInterval.SetText(fmt.Sprintf("%d", cur.ScreenshotIntervalSec))
 	toggleDedupe := widget.NewCheck(constants.TextDedupeTitle, func(v bool) {})
-	toggleDedupe.SetChecked(config.GetDedupeEnabled())
-	valueLabel := widget.NewLabel(fmt.Sprintf("%d", config.GetDedupeThreshold()))
+	toggleDedupe.SetChecked(cur.DedupeEnabled)
+	valueLabel := widget.NewLabel(fmt.Sprintf("%d", cur.DedupeThreshold))
 	sliderThreshold := widget.NewSlider(1, 100)
 	sliderThreshold.Step = 1
-	sliderThreshold.Value = float64(config.GetDedupeThreshold())
+	sliderThreshold.Value = float64(cur.DedupeThreshold)
 	sliderThreshold.OnChanged = func(v float64) {
 		valueLabel.SetText(fmt.Sprintf("%d", int(v)))
 	}
@@ -213,13 +335,65 @@ func onSettingsButtonTapped(_ fyne.App, autoEnabled *bool, autoStopChanPtr *chan
 		}
 	}
 	toggleAutoStart := widget.NewCheck(constants.TextAutoStartTitle, func(v bool) {})
-	toggleAutoStart.SetChecked(config.GetAutostartEnabled())
+	toggleAutoStart.SetChecked(cur.AutostartEnabled)
 	toggleAutoCapture := widget.NewCheck(constants.TextAutoCaptureTitle, func(v bool) {})
-	toggleAutoCapture.SetChecked(config.GetAutoCaptureEnabled())
+	toggleAutoCapture.SetChecked(cur.AutoCaptureEnabled)
 	toggleSilentStart := widget.NewCheck(constants.TextSilentStartTitle, func(v bool) {})
-	toggleSilentStart.SetChecked(config.GetSilentStartEnabled())
+	toggleSilentStart.SetChecked(cur.SilentStartEnabled)
+	entryIdleMinutes := widget.NewEntry()
+	entryIdleMinutes.SetText(fmt.Sprintf("%d", cur.IdlePauseMinutes))
+	togglePauseOnLock := widget.NewCheck(constants.TextPauseOnLockTitle, func(v bool) {})
+	togglePauseOnLock.SetChecked(cur.PauseOnLockEnabled)
+	idleRow := container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel(constants.TextIdleMinutesTitle), nil, entryIdleMinutes),
+		togglePauseOnLock,
+	)
+	toggleIdlePause := widget.NewCheck(constants.TextIdlePauseTitle, func(v bool) {
+		if v {
+			idleRow.Show()
+		} else {
+			idleRow.Hide()
+		}
+	})
+	toggleIdlePause.SetChecked(cur.IdlePauseEnabled)
+	if !toggleIdlePause.Checked {
+		idleRow.Hide()
+	}
+	entryBlankTolerance := widget.NewEntry()
+	entryBlankTolerance.SetText(fmt.Sprintf("%d", cur.BlankTolerance))
+	blankActions := []string{config.BlankActionSkip, config.BlankActionRetry}
+	selectBlankAction := widget.NewSelect([]string{constants.TextBlankActionSkip, constants.TextBlankActionRetry}, nil)
+	selectBlankAction.SetSelectedIndex(0)
+	if cur.BlankAction == config.BlankActionRetry {
+		selectBlankAction.SetSelectedIndex(1)
+	}
+	blankRow := container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel(constants.TextBlankToleranceTitle), nil, entryBlankTolerance),
+		selectBlankAction,
+	)
+	toggleBlankDetect := widget.NewCheck(constants.TextBlankDetectTitle, func(v bool) {
+		if v {
+			blankRow.Show()
+		} else {
+			blankRow.Hide()
+		}
+	})
+	toggleBlankDetect.SetChecked(cur.BlankDetectEnabled)
+	if !toggleBlankDetect.Checked {
+		blankRow.Hide()
+	}
 	chooseBtn := widget.NewButton(constants.TextChoose, func() {
-		if p, err := sys_utils.PickFolder(); err == nil && strings.TrimSpace(p) != "" {
+		p, err := sys_utils.PickFolder()
+		if errors.Is(err, errors.ErrUnsupported) {
+			// 没有原生对话框的平台使用 Fyne 内置的文件夹选择
+			dialog.ShowFolderOpen(func(u fyne.ListableURI, err error) {
+				if err == nil && u != nil {
+					entryRoot.SetText(u.Path())
+				}
+			}, w)
+			return
+		}
+		if err == nil && strings.TrimSpace(p) != "" {
 			entryRoot.SetText(p)
 		}
 	})
@@ -227,35 +401,46 @@ func onSettingsButtonTapped(_ fyne.App, autoEnabled *bool, autoStopChanPtr *chan
 		entryRoot.SetText(config.GetDefaultStorageRoot())
 	})
 	save := widget.NewButton(constants.TextSave, func() {
-		root := entryRoot.Text
-		config.SetStorageRoot(root)
-		n := 5
-		if v, err := strconv.Atoi(strings.TrimSpace(entryInterval.Text)); err == nil {
-			if v > 0 {
-				n = v
+		// 先在配置副本上应用并校验全部设置，有误时不保存任何一项
+		c := store.Snapshot()
+		var errs config.ValidationError
+		atoi := func(field, text string) int {
+			v, err := strconv.Atoi(strings.TrimSpace(text))
+			if err != nil {
+				errs = append(errs, config.FieldError{Field: field, Message: constants.TextIntegerRequired})
 			}
+			return v
 		}
-		config.SetScreenshotIntervalSec(n)
-		config.SetDedupeEnabled(toggleDedupe.Checked)
-		*dedupeEnabled = toggleDedupe.Checked
-		// threshold
-		th := int(sliderThreshold.Value)
-		if th < 1 {
-			th = 1
-		} else if th > 100 {
-			th = 100
+		c.StorageRoot = strings.TrimSpace(entryRoot.Text)
+		c.ScreenshotIntervalSec = atoi("screenshot_interval_sec", entryInterval.Text)
+		c.DedupeEnabled = toggleDedupe.Checked
+		c.DedupeThreshold = int(sliderThreshold.Value)
+		c.AutostartEnabled = toggleAutoStart.Checked
+		c.AutoCaptureEnabled = toggleAutoCapture.Checked
+		c.SilentStartEnabled = toggleSilentStart.Checked
+		c.IdlePauseEnabled = toggleIdlePause.Checked
+		c.IdlePauseMinutes = atoi("idle_pause_minutes", entryIdleMinutes.Text)
+		c.PauseOnLockEnabled = togglePauseOnLock.Checked
+		c.BlankDetectEnabled = toggleBlankDetect.Checked
+		c.BlankTolerance = atoi("blank_tolerance", entryBlankTolerance.Text)
+		if i := selectBlankAction.SelectedIndex(); i >= 0 {
+			c.BlankAction = blankActions[i]
 		}
-		config.SetDedupeThreshold(th)
-		config.SetAutostartEnabled(toggleAutoStart.Checked)
-		config.SetAutoCaptureEnabled(toggleAutoCapture.Checked)
-		config.SetSilentStartEnabled(toggleSilentStart.Checked)
-		exe, _ := os.Executable()
-		if toggleAutoStart.Checked {
-			_ = sys_utils.EnableAutoStart(constants.TextAppTitle, exe)
-		} else {
-			_ = sys_utils.DisableAutoStart(constants.TextAppTitle)
+		errs = append(errs, c.ValidateSettings()...)
+		if len(errs) > 0 {
+			showError(fyne.CurrentApp(), constants.TextConfigInvalid, errs)
+			return
+		}
+
+		// 全部设置在一个事务中写入，订阅者只收到一次变更
+		if err := store.Update(func(cfg *config.AppConfig) error {
+			*cfg = c
+			return nil
+		}); err != nil {
+			showError(fyne.CurrentApp(), constants.TextConfigSaveError, err)
+			return
 		}
-		// 自动截图的重启在 settingsBtn 点击处理处统一完成
+		// 自启注册、截图周期等由配置变更订阅生效
 		w.Close()
 	})
 	cancel := widget.NewButton(constants.TextCancel, func() { w.Close() })
@@ -269,6 +454,10 @@ func onSettingsButtonTapped(_ fyne.App, autoEnabled *bool, autoStopChanPtr *chan
 		toggleAutoStart,
 		toggleAutoCapture,
 		toggleSilentStart,
+		toggleIdlePause,
+		idleRow,
+		toggleBlankDetect,
+		blankRow,
 		container.NewHBox(save, cancel),
 	)
 	wrapped := fynetooltip.AddWindowToolTipLayer(container.NewPadded(form), w.Canvas())
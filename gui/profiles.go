@@ -0,0 +1,53 @@
+package gui
+
+import (
+	"cron-shot/config"
+	"cron-shot/constants"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	fynetooltip "github.com/dweymouth/fyne-tooltip"
+)
+
+// showProfileCreate 打开新建配置方案窗口；新方案复制当前方案，创建成功后以方案名调用 onCreated
+func showProfileCreate(app fyne.App, store *config.Store, onCreated func(name string)) {
+	w := NewSingletonWindow(constants.TextProfileCreate)
+	entryName := widget.NewEntry()
+	entryName.PlaceHolder = constants.TextProfileName
+	save := func() {
+		name := strings.TrimSpace(entryName.Text)
+		if err := store.CreateProfile(name, ""); err != nil {
+			showError(app, constants.TextProfileCreateError, err)
+			return
+		}
+		w.Close()
+		if onCreated != nil {
+			onCreated(name)
+		}
+	}
+	entryName.OnSubmitted = func(string) { save() }
+	btnSave := widget.NewButton(constants.TextSave, save)
+	btnCancel := widget.NewButton(constants.TextCancel, func() { w.Close() })
+	form := container.NewVBox(
+		widget.NewLabel(constants.TextProfileName),
+		entryName,
+		widget.NewLabel(constants.TextProfileCreateHint),
+		container.NewHBox(btnSave, btnCancel),
+	)
+	wrapped := fynetooltip.AddWindowToolTipLayer(container.NewPadded(form), w.Canvas())
+	w.SetContent(wrapped)
+	w.Resize(fyne.NewSize(360, 160))
+	w.SetOnClosed(func() { fynetooltip.DestroyWindowToolTipLayer(w.Canvas()) })
+	w.Show()
+	w.Canvas().Focus(entryName)
+}
+
+// windowTitle 返回主窗口标题；非默认配置方案时附加方案名
+func windowTitle(store *config.Store) string {
+	if p := store.ActiveProfile(); p != config.DefaultProfile {
+		return constants.TextAppTitle + " - " + p
+	}
+	return constants.TextAppTitle
+}
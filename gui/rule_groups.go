@@ -0,0 +1,218 @@
+package gui
+
+import (
+	"cron-shot/config"
+	"cron-shot/constants"
+	"errors"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	fynetooltip "github.com/dweymouth/fyne-tooltip"
+)
+
+// showRuleGroups 打开规则组管理窗口：新增/删除规则组、整体启用或停用、编辑时间表
+// 每项修改都在配置事务中按组名作用于最新的规则组，列表随配置变化（托盘、命令行、外部修改）刷新
+func showRuleGroups(app fyne.App, store config.Provider) {
+	w := NewSingletonWindow(constants.TextRuleGroups)
+	groups := store.Snapshot().RuleGroups
+	var list *widget.List
+	cancel := config.OnChange(store, config.ChangeRules, func(_ config.Change, _, cur config.AppConfig) {
+		fyne.Do(func() {
+			groups = cur.RuleGroups
+			list.Refresh()
+		})
+	})
+	// modify 在配置中对名为 name 的规则组执行 fn；组已被删除时忽略
+	modify := func(name string, fn func(c *config.AppConfig, i int)) {
+		_ = store.Update(func(c *config.AppConfig) error {
+			for i := range c.RuleGroups {
+				if c.RuleGroups[i].Name == name {
+					fn(c, i)
+					return nil
+				}
+			}
+			return errors.New("rule group not found: " + name)
+		})
+	}
+
+	list = widget.NewList(
+		func() int { return len(groups) },
+		func() fyne.CanvasObject {
+			label := NewHoverLabel("Template")
+			label.label.Wrapping = fyne.TextWrapOff
+			label.label.Truncation = fyne.TextTruncateEllipsis
+			btnToggle := widget.NewButton(constants.TextActivate, nil)
+			btnSchedule := widget.NewButton(constants.TextRuleGroupSchedule, nil)
+			btnDelete := widget.NewButton(constants.TextDelete, nil)
+			smallSize := fyne.NewSize(60, 26)
+			btnRow := container.NewHBox(
+				container.NewGridWrap(smallSize, btnToggle),
+				container.NewGridWrap(smallSize, btnSchedule),
+				container.NewGridWrap(smallSize, btnDelete),
+			)
+			right := container.NewVBox(layout.NewSpacer(), btnRow, layout.NewSpacer())
+			return container.NewBorder(nil, nil, nil, right, label)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			if i >= len(groups) {
+				return
+			}
+			c := o.(*fyne.Container)
+			label := c.Objects[0].(*HoverLabel)
+			btnRow := c.Objects[1].(*fyne.Container).Objects[1].(*fyne.Container)
+			toggle := btnRow.Objects[0].(*fyne.Container).Objects[0].(*widget.Button)
+			schedule := btnRow.Objects[1].(*fyne.Container).Objects[0].(*widget.Button)
+			del := btnRow.Objects[2].(*fyne.Container).Objects[0].(*widget.Button)
+
+			g := groups[i]
+			text := g.Name
+			if g.Schedule != nil {
+				text += " [" + g.Schedule.Start + "-" + g.Schedule.End + "]"
+			}
+			if g.Active(time.Now()) {
+				text += constants.TextRuleGroupActive
+			}
+			label.SetText(text)
+			if g.Enabled {
+				toggle.SetText(constants.TextDeactivate)
+				toggle.Importance = widget.HighImportance
+			} else {
+				toggle.SetText(constants.TextActivate)
+				toggle.Importance = widget.MediumImportance
+			}
+			toggle.Refresh()
+			toggle.OnTapped = func() {
+				modify(g.Name, func(c *config.AppConfig, i int) { c.RuleGroups[i].Enabled = !c.RuleGroups[i].Enabled })
+			}
+			schedule.OnTapped = func() {
+				showGroupSchedule(app, g.Schedule, func(s *config.GroupSchedule) {
+					modify(g.Name, func(c *config.AppConfig, i int) {
+						// 首次设置时间表时取消手动启用，否则时间表不起作用；之后仍可手动启用
+						if c.RuleGroups[i].Schedule == nil && s != nil {
+							c.RuleGroups[i].Enabled = false
+						}
+						c.RuleGroups[i].Schedule = s
+					})
+				})
+			}
+			del.OnTapped = func() {
+				modify(g.Name, func(c *config.AppConfig, i int) {
+					c.RuleGroups = append(c.RuleGroups[:i], c.RuleGroups[i+1:]...)
+				})
+			}
+		},
+	)
+
+	entryName := widget.NewEntry()
+	entryName.PlaceHolder = constants.PlaceholderRuleGroup
+	btnAdd := widget.NewButton(constants.TextAdd, func() {
+		name := strings.TrimSpace(entryName.Text)
+		if name == "" {
+			return
+		}
+		err := store.Update(func(c *config.AppConfig) error {
+			for _, g := range c.RuleGroups {
+				if g.Name == name {
+					return errors.New(name)
+				}
+			}
+			c.RuleGroups = append(c.RuleGroups, config.RuleGroup{Name: name, Enabled: true})
+			return nil
+		})
+		if err != nil {
+			showError(app, constants.TextRuleGroupExists, err)
+			return
+		}
+		entryName.SetText("")
+	})
+
+	top := container.NewBorder(nil, nil, nil, btnAdd, entryName)
+	content := container.NewBorder(top, nil, nil, nil, NewStyledListContainer(constants.TextRuleGroupsHeader, list))
+	wrapped := fynetooltip.AddWindowToolTipLayer(container.NewPadded(content), w.Canvas())
+	w.SetContent(wrapped)
+	w.Resize(fyne.NewSize(480, 360))
+	w.SetOnClosed(func() {
+		cancel()
+		fynetooltip.DestroyWindowToolTipLayer(w.Canvas())
+	})
+	w.Show()
+}
+
+// ensureRuleGroup 规则引用了尚未定义的规则组时自动创建（默认启用），使其出现在规则组管理与托盘中
+func ensureRuleGroup(store config.Provider, name string) {
+	if name == "" {
+		return
+	}
+	_ = store.Update(func(c *config.AppConfig) error {
+		for _, g := range c.RuleGroups {
+			if g.Name == name {
+				return nil
+			}
+		}
+		c.RuleGroups = append(c.RuleGroups, config.RuleGroup{Name: name, Enabled: true})
+		return nil
+	})
+}
+
+// showGroupSchedule 打开时间表编辑窗口；保存时回调 onSave（未启用时间表时传入 nil）
+func showGroupSchedule(app fyne.App, s *config.GroupSchedule, onSave func(*config.GroupSchedule)) {
+	w := app.NewWindow(constants.TextRuleGroupSchedule)
+	checkEnabled := widget.NewCheck(constants.TextScheduleEnabled, nil)
+	checkEnabled.SetChecked(s != nil)
+	if s == nil {
+		s = &config.GroupSchedule{Start: "09:00", End: "18:00"}
+	}
+	entryStart := widget.NewEntry()
+	entryStart.SetText(s.Start)
+	entryEnd := widget.NewEntry()
+	entryEnd.SetText(s.End)
+	dayChecks := make([]*widget.Check, len(constants.TextWeekdays))
+	dayRow := container.NewHBox()
+	for d, text := range constants.TextWeekdays {
+		dayChecks[d] = widget.NewCheck(text, nil)
+		dayRow.Add(dayChecks[d])
+	}
+	for _, d := range s.Days {
+		if d >= 0 && d < len(dayChecks) {
+			dayChecks[d].SetChecked(true)
+		}
+	}
+
+	btnSave := widget.NewButton(constants.TextSave, func() {
+		if !checkEnabled.Checked {
+			onSave(nil)
+			w.Close()
+			return
+		}
+		ns := &config.GroupSchedule{Start: strings.TrimSpace(entryStart.Text), End: strings.TrimSpace(entryEnd.Text)}
+		for d, c := range dayChecks {
+			if c.Checked {
+				ns.Days = append(ns.Days, d)
+			}
+		}
+		if err := ns.Validate(); err != nil {
+			showError(app, constants.TextScheduleError, err)
+			return
+		}
+		onSave(ns)
+		w.Close()
+	})
+	btnCancel := widget.NewButton(constants.TextCancel, func() { w.Close() })
+	inner := container.NewVBox(
+		checkEnabled,
+		widget.NewLabel(constants.TextScheduleStart),
+		entryStart,
+		widget.NewLabel(constants.TextScheduleEnd),
+		entryEnd,
+		widget.NewLabel(constants.TextScheduleDays),
+		dayRow,
+		container.NewHBox(btnSave, btnCancel),
+	)
+	w.SetContent(container.NewPadded(inner))
+	w.Resize(fyne.NewSize(360, 300))
+	w.Show()
+}
@@ -0,0 +1,105 @@
+package gui
+
+import (
+	"cron-shot/config"
+	"cron-shot/constants"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	fynetooltip "github.com/dweymouth/fyne-tooltip"
+)
+
+// 合并方式与显示文本的对应关系（保持选项顺序）
+var mergeOptions = []struct{ Strategy, Text string }{
+	{config.MergeAppend, constants.TextMergeAppend},
+	{config.MergeSkipDuplicates, constants.TextMergeSkipDuplicates},
+	{config.MergeReplace, constants.TextMergeReplace},
+}
+
+// showRulePack 打开规则导入/导出窗口；导入的规则经配置变更通知同步到规则列表
+func showRulePack(app fyne.App, store *config.Store) {
+	w := NewSingletonWindow(constants.TextRulePack)
+	entryPath := widget.NewEntry()
+	entryPath.PlaceHolder = constants.PlaceholderRulePackPath
+	filter := storage.NewExtensionFileFilter([]string{".json", ".yaml", ".yml"})
+
+	btnBrowse := widget.NewButton(constants.TextRulePackBrowse, func() {
+		d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err == nil && r != nil {
+				entryPath.SetText(r.URI().Path())
+				_ = r.Close()
+			}
+		}, w)
+		d.SetFilter(filter)
+		d.Show()
+	})
+
+	var texts []string
+	for _, o := range mergeOptions {
+		texts = append(texts, o.Text)
+	}
+	selectStrategy := widget.NewSelect(texts, nil)
+	selectStrategy.SetSelectedIndex(0)
+
+	status := widget.NewLabel("")
+	btnImport := widget.NewButton(constants.TextRulePackImport, func() {
+		path := strings.TrimSpace(entryPath.Text)
+		if path == "" {
+			return
+		}
+		strategy := mergeOptions[0].Strategy
+		if i := selectStrategy.SelectedIndex(); i >= 0 {
+			strategy = mergeOptions[i].Strategy
+		}
+		n, err := store.ImportRulePack(path, strategy)
+		if err != nil {
+			showError(app, constants.TextRulePackImportError, err)
+			return
+		}
+		status.SetText(fmt.Sprintf(constants.TextRulePackImported, n))
+	})
+	btnExport := widget.NewButton(constants.TextRulePackExport, func() {
+		export := func(path string) {
+			rules := store.Snapshot().Rules
+			if err := config.ExportRulePack(path, constants.TextAppTitle, rules); err != nil {
+				showError(app, constants.TextRulePackExportError, err)
+				return
+			}
+			entryPath.SetText(path)
+			status.SetText(fmt.Sprintf(constants.TextRulePackExported, len(rules)))
+		}
+		if path := strings.TrimSpace(entryPath.Text); path != "" {
+			export(path)
+			return
+		}
+		d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+			if err == nil && wc != nil {
+				path := wc.URI().Path()
+				_ = wc.Close()
+				export(path)
+			}
+		}, w)
+		d.SetFileName("rules.yaml")
+		d.SetFilter(filter)
+		d.Show()
+	})
+
+	inner := container.NewVBox(
+		widget.NewLabel(constants.TextRulePackFile),
+		container.NewBorder(nil, nil, nil, btnBrowse, entryPath),
+		widget.NewLabel(constants.TextRulePackStrategy),
+		selectStrategy,
+		container.NewGridWithColumns(2, btnImport, btnExport),
+		status,
+	)
+	wrapped := fynetooltip.AddWindowToolTipLayer(container.NewPadded(inner), w.Canvas())
+	w.SetContent(wrapped)
+	w.Resize(fyne.NewSize(560, 420))
+	w.SetOnClosed(func() { fynetooltip.DestroyWindowToolTipLayer(w.Canvas()) })
+	w.Show()
+}
@@ -0,0 +1,122 @@
+package gui
+
+import (
+	appctrl "cron-shot/app"
+	"cron-shot/config"
+	"cron-shot/constants"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	fynetooltip "github.com/dweymouth/fyne-tooltip"
+)
+
+// showRuleTester 打开规则测试窗口：列出当前进程的窗口标题与用户输入的示例标题，
+// 选中标题后显示命中的规则、捕获组、解析出的文件夹与最终保存路径
+func showRuleTester(store config.Provider, rules func() *appctrl.RuleSet, proc string, windows func() []string) {
+	w := NewSingletonWindow(constants.TextRuleTester)
+	var titles []string
+	live := 0 // titles 中前 live 项为进程窗口，其余为示例标题
+	var samples []string
+
+	result := widget.NewLabel("")
+	result.Wrapping = fyne.TextWrapBreak
+	show := func(title string) {
+		result.SetText(formatRuleTest(rules().Test(title, proc, store.Snapshot().StorageRoot, time.Now())))
+	}
+
+	var list *widget.List
+	reload := func() {
+		titles = append(windows(), samples...)
+		live = len(titles) - len(samples)
+		list.UnselectAll()
+		list.Refresh()
+	}
+	list = widget.NewList(
+		func() int { return len(titles) },
+		func() fyne.CanvasObject {
+			l := NewHoverLabel("Template")
+			l.label.Wrapping = fyne.TextWrapOff
+			l.label.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			if i >= len(titles) {
+				return
+			}
+			prefix := constants.TextRuleTesterLive
+			if i >= live {
+				prefix = constants.TextRuleTesterSamples
+			}
+			_, hl := rules().Match(titles[i])
+			lbl := o.(*HoverLabel)
+			lbl.SetText("[" + prefix + "] " + titles[i])
+			lbl.SetHighlighted(hl)
+			lbl.Refresh()
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		if id < len(titles) {
+			show(titles[id])
+		}
+	}
+
+	entrySample := widget.NewEntry()
+	entrySample.PlaceHolder = constants.PlaceholderSampleTitle
+	entrySample.OnSubmitted = func(s string) {
+		if s == "" {
+			return
+		}
+		samples = append(samples, s)
+		entrySample.SetText("")
+		reload()
+		show(s)
+	}
+	btnRefresh := widget.NewButton(constants.TextRuleTesterRefresh, reload)
+	reload()
+
+	top := container.NewBorder(nil, nil, nil, btnRefresh, entrySample)
+	labelResult := widget.NewLabel(constants.TextRuleTesterResult)
+	labelResult.TextStyle = fyne.TextStyle{Bold: true}
+	bottom := container.NewBorder(labelResult, nil, nil, nil, container.NewVScroll(result))
+	split := container.NewVSplit(list, bottom)
+	split.Offset = 0.5
+	content := container.NewBorder(top, nil, nil, nil, split)
+	wrapped := fynetooltip.AddWindowToolTipLayer(container.NewPadded(content), w.Canvas())
+	w.SetContent(wrapped)
+	w.Resize(fyne.NewSize(560, 480))
+	w.SetOnClosed(func() { fynetooltip.DestroyWindowToolTipLayer(w.Canvas()) })
+	w.Show()
+}
+
+// formatRuleTest 将测试结果格式化为多行文本
+func formatRuleTest(r appctrl.RuleTestResult) string {
+	var b strings.Builder
+	b.WriteString(r.Title + "\n\n")
+	for _, m := range r.Matches {
+		b.WriteString(constants.TextRuleTesterRule + ruleLabel(fromConfigRules([]config.AppRule{*m.Rule})[0]))
+		if m.HasConditions {
+			b.WriteString(constants.TextRuleTesterConditions)
+		}
+		b.WriteString("\n")
+		if len(m.PatternGroups) > 0 {
+			b.WriteString(constants.TextRuleTesterGroups + strings.Join(m.PatternGroups, ", ") + "\n")
+		}
+		if len(m.StorageGroups) > 0 {
+			b.WriteString(constants.TextRuleTesterStorage + strings.Join(m.StorageGroups, ", ") + "\n")
+		}
+		b.WriteString(constants.TextRuleTesterFolder + m.Folder + "\n")
+		if m.Fixed != "" {
+			b.WriteString(constants.TextRuleTesterFixed + m.Fixed + "\n")
+		}
+		b.WriteString(constants.TextRuleTesterPath + m.Path + "\n\n")
+	}
+	if r.ExcludedBy != nil {
+		b.WriteString(constants.TextRuleTesterExcluded + r.ExcludedBy.Pattern + "\n")
+	} else if len(r.Matches) == 0 {
+		b.WriteString(constants.TextRuleTesterNoMatch + "\n")
+	}
+	return b.String()
+}
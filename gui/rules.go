@@ -1,9 +1,12 @@
 package gui
 
 import (
+	appctrl "cron-shot/app"
 	"cron-shot/config"
 	"cron-shot/constants"
-	"regexp"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -12,18 +15,32 @@ import (
 	fynetooltip "github.com/dweymouth/fyne-tooltip"
 )
 
+// maxRuleUndo 可撤销的最大步数
+const maxRuleUndo = 20
+
 // RulesUI 组件
+// 支持勾选多条规则后批量启用/停用/删除、上移/下移调整顺序、复制，以及撤销上一次修改；
+// 双击规则文本可直接编辑匹配表达式
 type RulesUI struct {
 	Container      *fyne.Container
 	Rules          []WindowRule
 	RuleList       *widget.List
 	OnRulesChanged func()
+	app            fyne.App
+	store          config.Provider
+	undo           [][]WindowRule
+	btnUndo        *widget.Button
+	editing        int // 正在行内编辑的规则下标，-1 表示无
 }
 
 // NewRulesUI 创建规则列表部分的UI
-func NewRulesUI(app fyne.App) *RulesUI {
+// 规则的修改通过 store 写入配置，并订阅规则变化使列表与配置保持一致
+func NewRulesUI(app fyne.App, store config.Provider) *RulesUI {
 	ui := &RulesUI{
-		Rules: []WindowRule{},
+		Rules:   []WindowRule{},
+		app:     app,
+		store:   store,
+		editing: -1,
 	}
 
 	// 正则表达式控件
@@ -35,23 +52,16 @@ func NewRulesUI(app fyne.App) *RulesUI {
 		if regStr == "" {
 			return
 		}
-		// 验证正则表达式是否有效
-		if _, err := regexp.Compile(regStr); err != nil {
-			showError(app, "正则表达式错误", err)
+		// 使用规则引擎验证正则表达式是否有效（与截图匹配同一引擎）
+		if err := appctrl.ValidatePattern(regStr); err != nil {
+			showError(app, constants.TextRegexError, err)
 			return
 		}
 
+		ui.pushUndo()
 		ui.Rules = append(ui.Rules, WindowRule{Pattern: regStr, Enabled: true})
 		entryRegex.SetText("")
-		ui.RuleList.Refresh()
-		if ui.OnRulesChanged != nil {
-			ui.OnRulesChanged()
-		}
-		var cfgRules []config.AppRule
-		for _, r := range ui.Rules {
-			cfgRules = append(cfgRules, config.AppRule{Pattern: r.Pattern, Enabled: r.Enabled, StorageRule: r.StorageRule, FixedFolder: r.FixedFolder})
-		}
-		config.SetRules(cfgRules)
+		ui.commit()
 	})
 
 	// 规则列表组件
@@ -60,9 +70,12 @@ func NewRulesUI(app fyne.App) *RulesUI {
 			return len(ui.Rules)
 		},
 		func() fyne.CanvasObject {
+			check := widget.NewCheck("", nil)
 			label := NewHoverLabel("Template")
 			label.label.Wrapping = fyne.TextWrapOff
 			label.label.Truncation = fyne.TextTruncateEllipsis
+			entry := newInlineEntry()
+			entry.Hide()
 
 			btnToggle := widget.NewButton(constants.TextActivate, nil)
 			btnConfig := widget.NewButton(constants.TextConfig, nil)
@@ -74,11 +87,13 @@ func NewRulesUI(app fyne.App) *RulesUI {
 			wrapDelete := container.NewGridWrap(smallSize, btnDelete)
 			btnRow := container.NewHBox(wrapToggle, wrapConfig, wrapDelete)
 			right := container.NewVBox(layout.NewSpacer(), btnRow, layout.NewSpacer())
-			return container.NewBorder(nil, nil, nil, right, label)
+			return container.NewBorder(nil, nil, check, right, container.NewStack(label, entry))
 		},
 		func(i widget.ListItemID, o fyne.CanvasObject) {
 			c := o.(*fyne.Container)
 			var label *HoverLabel
+			var entry *inlineEntry
+			var check *widget.Check
 			var buttons []*widget.Button
 			var walk func(obj fyne.CanvasObject)
 			walk = func(obj fyne.CanvasObject) {
@@ -87,6 +102,10 @@ func NewRulesUI(app fyne.App) *RulesUI {
 					if label == nil {
 						label = t
 					}
+				case *inlineEntry:
+					entry = t
+				case *widget.Check:
+					check = t
 				case *widget.Button:
 					buttons = append(buttons, t)
 				case *fyne.Container:
@@ -97,12 +116,62 @@ func NewRulesUI(app fyne.App) *RulesUI {
 			}
 			walk(c)
 
-			if i >= len(ui.Rules) || label == nil || len(buttons) < 3 {
+			if i >= len(ui.Rules) || label == nil || entry == nil || check == nil || len(buttons) < 3 {
 				return
 			}
 
 			rule := ui.Rules[i]
-			label.SetText(rule.Pattern)
+			label.SetText(ruleLabel(rule))
+
+			// 勾选状态随规则移动，先解除回调再设置，避免触发修改
+			check.OnChanged = nil
+			check.SetChecked(rule.selected)
+			check.OnChanged = func(b bool) {
+				if i < len(ui.Rules) {
+					ui.Rules[i].selected = b
+				}
+			}
+
+			// 双击进入行内编辑；回车保存（校验失败时保持编辑），Esc 或失去焦点取消
+			label.OnDoubleTapped = func() {
+				ui.editing = i
+				ui.RuleList.RefreshItem(i)
+			}
+			if ui.editing == i {
+				label.Hide()
+				entry.Show()
+				entry.SetText(rule.Pattern)
+				entry.onCancel = func() {
+					if ui.editing == i {
+						ui.editing = -1
+						ui.RuleList.RefreshItem(i)
+					}
+				}
+				entry.OnSubmitted = func(s string) {
+					if err := appctrl.ValidatePattern(s); err != nil || s == "" {
+						if err != nil {
+							showError(app, constants.TextRegexError, err)
+						}
+						return
+					}
+					ui.editing = -1
+					if i < len(ui.Rules) && ui.Rules[i].Pattern != s {
+						ui.pushUndo()
+						ui.Rules[i].Pattern = s
+						ui.commit()
+						return
+					}
+					ui.RuleList.RefreshItem(i)
+				}
+				if cv := fyne.CurrentApp().Driver().CanvasForObject(entry); cv != nil {
+					cv.Focus(entry)
+				}
+			} else {
+				entry.onCancel = nil
+				entry.OnSubmitted = nil
+				entry.Hide()
+				label.Show()
+			}
 
 			toggle := buttons[0]
 			configBtn := buttons[1]
@@ -118,71 +187,21 @@ func NewRulesUI(app fyne.App) *RulesUI {
 
 			toggle.OnTapped = func() {
 				if i < len(ui.Rules) {
+					ui.pushUndo()
 					ui.Rules[i].Enabled = !ui.Rules[i].Enabled
-					ui.RuleList.Refresh()
-					if ui.OnRulesChanged != nil {
-						ui.OnRulesChanged()
-					}
-					var cfgRules []config.AppRule
-					for _, r := range ui.Rules {
-						cfgRules = append(cfgRules, config.AppRule{Pattern: r.Pattern, Enabled: r.Enabled, StorageRule: r.StorageRule, FixedFolder: r.FixedFolder})
-					}
-					config.SetRules(cfgRules)
+					ui.commit()
 				}
 			}
 			configBtn.OnTapped = func() {
-				w := NewSingletonWindow(constants.TextStorageRuleTitle)
-				entryRule := widget.NewEntry()
-				entryRule.PlaceHolder = constants.PlaceholderStorageRule
-				entryRule.SetText(ui.Rules[i].StorageRule)
-				entryFixed := widget.NewEntry()
-				entryFixed.PlaceHolder = constants.PlaceholderFixedFolder
-				entryFixed.SetText(ui.Rules[i].FixedFolder)
-				btnSave := widget.NewButton(constants.TextSave, func() {
-					ui.Rules[i].StorageRule = entryRule.Text
-					ui.Rules[i].FixedFolder = entryFixed.Text
-					ui.RuleList.Refresh()
-					if ui.OnRulesChanged != nil {
-						ui.OnRulesChanged()
-					}
-					var cfgRules []config.AppRule
-					for _, r := range ui.Rules {
-						cfgRules = append(cfgRules, config.AppRule{Pattern: r.Pattern, Enabled: r.Enabled, StorageRule: r.StorageRule, FixedFolder: r.FixedFolder})
-					}
-					config.SetRules(cfgRules)
-					w.Close()
-				})
-				btnCancel := widget.NewButton(constants.TextCancel, func() { w.Close() })
-				labelRule := widget.NewLabel(constants.TextStorageRuleTitle)
-				labelFixed := widget.NewLabel(constants.TextFixedFolderTitle)
-				inner := container.NewVBox(
-					labelRule,
-					entryRule,
-					labelFixed,
-					entryFixed,
-					container.NewHBox(btnSave, btnCancel),
-				)
-				padded := container.NewPadded(inner)
-				wrapped := fynetooltip.AddWindowToolTipLayer(padded, w.Canvas())
-				w.SetContent(wrapped)
-				w.Resize(fyne.NewSize(420, 220))
-				w.SetOnClosed(func() {
-					fynetooltip.DestroyWindowToolTipLayer(w.Canvas())
-				})
-				w.Show()
+				if i < len(ui.Rules) {
+					ui.showRuleConfig(i)
+				}
 			}
 			deleteBtn.OnTapped = func() {
 				if i < len(ui.Rules) {
+					ui.pushUndo()
 					ui.Rules = append(ui.Rules[:i], ui.Rules[i+1:]...)
-					ui.RuleList.Refresh()
-					if ui.OnRulesChanged != nil {
-						ui.OnRulesChanged()
-					}
-					var cfgRules []config.AppRule
-					for _, r := range ui.Rules {
-						cfgRules = append(cfgRules, config.AppRule{Pattern: r.Pattern, Enabled: r.Enabled, StorageRule: r.StorageRule, FixedFolder: r.FixedFolder})
-					}
-					config.SetRules(cfgRules)
+					ui.commit()
 				}
 			}
 		},
@@ -196,14 +215,321 @@ func NewRulesUI(app fyne.App) *RulesUI {
 	labelWindow.TextStyle = fyne.TextStyle{Bold: true}
 	windowRow := container.NewBorder(nil, nil, labelWindow, buttonAddRule, entryRegex)
 
-	// 3. 总体布局：上面是添加栏，下面是列表
+	// 3. 批量操作栏：作用于勾选的规则
+	checkAll := widget.NewCheck(constants.TextSelectAll, func(b bool) {
+		for i := range ui.Rules {
+			ui.Rules[i].selected = b
+		}
+		ui.RuleList.Refresh()
+	})
+	ui.btnUndo = widget.NewButton(constants.TextUndo, ui.Undo)
+	ui.btnUndo.Disable()
+	bulkRow := container.NewHBox(
+		checkAll,
+		widget.NewButton(constants.TextMoveUp, func() { ui.moveSelected(-1) }),
+		widget.NewButton(constants.TextMoveDown, func() { ui.moveSelected(1) }),
+		widget.NewButton(constants.TextDuplicate, ui.duplicateSelected),
+		widget.NewButton(constants.TextActivate, func() { ui.setSelectedEnabled(true) }),
+		widget.NewButton(constants.TextDeactivate, func() { ui.setSelectedEnabled(false) }),
+		widget.NewButton(constants.TextDelete, ui.deleteSelected),
+		layout.NewSpacer(),
+		ui.btnUndo,
+	)
+
+	// 4. 总体布局：上面是添加栏，下面是列表
 	// 使用 VBox 布局，使列表部分保持由 rect 撑开的固定高度 (150)，不会自动填满整个窗口
 	// 这样当条目超过5个时，列表内部会出现滚动条
 	content := container.NewVBox(
 		windowRow,
 		listContainer,
+		bulkRow,
 	)
 
 	ui.Container = content
+	config.OnChange(store, config.ChangeRules, func(config.Change, config.AppConfig, config.AppConfig) {
+		fyne.Do(ui.syncFromConfig)
+	})
 	return ui
 }
+
+// commit 刷新列表并将规则写入配置；OnRulesChanged 由随后的配置变更通知触发
+func (ui *RulesUI) commit() {
+	rules := toConfigRules(ui.Rules)
+	_ = ui.store.Update(func(c *config.AppConfig) error {
+		c.Rules = rules
+		return nil
+	})
+	ui.RuleList.Refresh()
+}
+
+// syncFromConfig 规则或规则组在配置中变化（界面、命令行、规则包导入或外部修改）后调用：
+// 配置中的规则与列表不同说明修改并非来自本列表，此时替换列表并清空撤销记录，
+// 避免撤销把旧规则写回配置、覆盖外部的修改；随后通知 OnRulesChanged；需在界面线程调用
+func (ui *RulesUI) syncFromConfig() {
+	rules := ui.store.Snapshot().Rules
+	if current := toConfigRules(ui.Rules); len(current) != 0 || len(rules) != 0 {
+		if !reflect.DeepEqual(current, rules) {
+			ui.ResetRules(fromConfigRules(rules))
+		}
+	}
+	ui.RuleList.Refresh()
+	if ui.OnRulesChanged != nil {
+		ui.OnRulesChanged()
+	}
+}
+
+// pushUndo 在修改规则前保存快照
+func (ui *RulesUI) pushUndo() {
+	snap := append([]WindowRule(nil), ui.Rules...)
+	ui.undo = append(ui.undo, snap)
+	if len(ui.undo) > maxRuleUndo {
+		ui.undo = ui.undo[len(ui.undo)-maxRuleUndo:]
+	}
+	if ui.btnUndo != nil {
+		ui.btnUndo.Enable()
+	}
+}
+
+// Undo 撤销上一次修改
+func (ui *RulesUI) Undo() {
+	if len(ui.undo) == 0 {
+		return
+	}
+	ui.Rules = ui.undo[len(ui.undo)-1]
+	ui.undo = ui.undo[:len(ui.undo)-1]
+	if len(ui.undo) == 0 && ui.btnUndo != nil {
+		ui.btnUndo.Disable()
+	}
+	ui.editing = -1
+	ui.commit()
+}
+
+// ResetRules 替换界面中的规则并清空撤销记录（如切换配置方案或规则被外部修改后，撤销不应跨越这些变化）
+func (ui *RulesUI) ResetRules(rules []WindowRule) {
+	ui.undo = nil
+	if ui.btnUndo != nil {
+		ui.btnUndo.Disable()
+	}
+	ui.Rules = rules
+	ui.editing = -1
+	ui.RuleList.Refresh()
+}
+
+// hasSelection 返回是否有勾选的规则
+func (ui *RulesUI) hasSelection() bool {
+	for _, r := range ui.Rules {
+		if r.selected {
+			return true
+		}
+	}
+	return false
+}
+
+// moveSelected 将勾选的规则整体上移（delta=-1）或下移（delta=1）一位，保持相对顺序
+func (ui *RulesUI) moveSelected(delta int) {
+	if !ui.hasSelection() {
+		return
+	}
+	n := len(ui.Rules)
+	// 已到达边界的连续勾选块不再移动
+	if (delta < 0 && ui.Rules[0].selected) || (delta > 0 && ui.Rules[n-1].selected) {
+		return
+	}
+	ui.pushUndo()
+	if delta < 0 {
+		for i := 1; i < n; i++ {
+			if ui.Rules[i].selected && !ui.Rules[i-1].selected {
+				ui.Rules[i], ui.Rules[i-1] = ui.Rules[i-1], ui.Rules[i]
+			}
+		}
+	} else {
+		for i := n - 2; i >= 0; i-- {
+			if ui.Rules[i].selected && !ui.Rules[i+1].selected {
+				ui.Rules[i], ui.Rules[i+1] = ui.Rules[i+1], ui.Rules[i]
+			}
+		}
+	}
+	ui.editing = -1
+	ui.commit()
+}
+
+// duplicateSelected 在每条勾选规则之后插入一份副本
+func (ui *RulesUI) duplicateSelected() {
+	if !ui.hasSelection() {
+		return
+	}
+	ui.pushUndo()
+	out := make([]WindowRule, 0, len(ui.Rules)*2)
+	for _, r := range ui.Rules {
+		out = append(out, r)
+		if r.selected {
+			cp := r
+			cp.selected = false
+			out = append(out, cp)
+		}
+	}
+	ui.Rules = out
+	ui.editing = -1
+	ui.commit()
+}
+
+// setSelectedEnabled 批量启用或停用勾选的规则
+func (ui *RulesUI) setSelectedEnabled(enabled bool) {
+	if !ui.hasSelection() {
+		return
+	}
+	ui.pushUndo()
+	for i := range ui.Rules {
+		if ui.Rules[i].selected {
+			ui.Rules[i].Enabled = enabled
+		}
+	}
+	ui.commit()
+}
+
+// deleteSelected 批量删除勾选的规则
+func (ui *RulesUI) deleteSelected() {
+	if !ui.hasSelection() {
+		return
+	}
+	ui.pushUndo()
+	out := ui.Rules[:0:0]
+	for _, r := range ui.Rules {
+		if !r.selected {
+			out = append(out, r)
+		}
+	}
+	ui.Rules = out
+	ui.editing = -1
+	ui.commit()
+}
+
+// showRuleConfig 打开第 i 条规则的配置窗口
+func (ui *RulesUI) showRuleConfig(i int) {
+	app := ui.app
+	w := NewSingletonWindow(constants.TextStorageRuleTitle)
+	entryRule := widget.NewEntry()
+	entryRule.PlaceHolder = constants.PlaceholderStorageRule
+	entryRule.SetText(ui.Rules[i].StorageRule)
+	entryFixed := widget.NewEntry()
+	entryFixed.PlaceHolder = constants.PlaceholderFixedFolder
+	entryFixed.SetText(ui.Rules[i].FixedFolder)
+	entryTemplate := widget.NewEntry()
+	entryTemplate.PlaceHolder = constants.PlaceholderFolderTemplate
+	entryTemplate.SetText(ui.Rules[i].FolderTemplate)
+	targetForm := newCaptureTargetForm(ui.Rules[i].Target, ui.Rules[i].Monitor, ui.Rules[i].Rect)
+	matchForm := newWindowMatchForm(ui.Rules[i].Match)
+	checkExclude := widget.NewCheck(constants.TextRuleExcludeTitle, nil)
+	checkExclude.SetChecked(ui.Rules[i].Exclude)
+	checkContinue := widget.NewCheck(constants.TextRuleContinueTitle, nil)
+	checkContinue.SetChecked(ui.Rules[i].Continue)
+	var groupNames []string
+	for _, g := range ui.store.Snapshot().RuleGroups {
+		groupNames = append(groupNames, g.Name)
+	}
+	entryGroup := widget.NewSelectEntry(groupNames)
+	entryGroup.SetText(ui.Rules[i].Group)
+	entryPriority := widget.NewEntry()
+	entryPriority.SetText(strconv.Itoa(ui.Rules[i].Priority))
+	btnSave := widget.NewButton(constants.TextSave, func() {
+		if i >= len(ui.Rules) {
+			w.Close()
+			return
+		}
+		target, monitor, rect, err := targetForm.Values()
+		if err != nil {
+			showError(app, constants.TextCaptureRectError, err)
+			return
+		}
+		priority, err := strconv.Atoi(strings.TrimSpace(entryPriority.Text))
+		if err != nil {
+			showError(app, constants.TextRulePriorityError, err)
+			return
+		}
+		if sr := strings.TrimSpace(entryRule.Text); sr != "" {
+			if err := appctrl.ValidatePattern(sr); err != nil {
+				showError(app, constants.TextRegexError, err)
+				return
+			}
+		}
+		match, err := matchForm.Values()
+		if err != nil {
+			showError(app, constants.TextMatchConditionError, err)
+			return
+		}
+		ui.pushUndo()
+		r := &ui.Rules[i]
+		r.StorageRule = entryRule.Text
+		r.FixedFolder = entryFixed.Text
+		r.FolderTemplate = strings.TrimSpace(entryTemplate.Text)
+		r.Target = target
+		r.Monitor = monitor
+		r.Rect = rect
+		r.Match = match
+		r.Exclude = checkExclude.Checked
+		r.Continue = checkContinue.Checked
+		r.Priority = priority
+		r.Group = strings.TrimSpace(entryGroup.Text)
+		ensureRuleGroup(ui.store, r.Group)
+		ui.commit()
+		w.Close()
+	})
+	btnCancel := widget.NewButton(constants.TextCancel, func() { w.Close() })
+	labelRule := widget.NewLabel(constants.TextStorageRuleTitle)
+	labelFixed := widget.NewLabel(constants.TextFixedFolderTitle)
+	inner := container.NewVBox(
+		labelRule,
+		entryRule,
+		widget.NewLabel(constants.TextFolderTemplateTitle),
+		entryTemplate,
+		labelFixed,
+		entryFixed,
+		widget.NewLabel(constants.TextRuleGroupTitle),
+		entryGroup,
+		widget.NewLabel(constants.TextRulePriorityTitle),
+		entryPriority,
+		checkExclude,
+		checkContinue,
+		targetForm.Container,
+		matchForm.Container,
+		container.NewHBox(btnSave, btnCancel),
+	)
+	padded := container.NewPadded(inner)
+	wrapped := fynetooltip.AddWindowToolTipLayer(container.NewVScroll(padded), w.Canvas())
+	w.SetContent(wrapped)
+	w.Resize(fyne.NewSize(420, 560))
+	w.SetOnClosed(func() {
+		fynetooltip.DestroyWindowToolTipLayer(w.Canvas())
+	})
+	w.Show()
+}
+
+// inlineEntry 行内编辑用的输入框：Esc 或失去焦点时取消编辑
+type inlineEntry struct {
+	widget.Entry
+	onCancel func()
+}
+
+// newInlineEntry 创建行内编辑输入框
+func newInlineEntry() *inlineEntry {
+	e := &inlineEntry{}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *inlineEntry) TypedKey(ev *fyne.KeyEvent) {
+	if ev.Name == fyne.KeyEscape {
+		if e.onCancel != nil {
+			e.onCancel()
+		}
+		return
+	}
+	e.Entry.TypedKey(ev)
+}
+
+func (e *inlineEntry) FocusLost() {
+	e.Entry.FocusLost()
+	if e.onCancel != nil {
+		e.onCancel()
+	}
+}
@@ -0,0 +1,139 @@
+package gui
+
+import (
+	appctrl "cron-shot/app"
+	"cron-shot/config"
+	"cron-shot/constants"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// windowMatchForm 标题之外的窗口匹配条件表单
+type windowMatchForm struct {
+	Container     *fyne.Container
+	selectMode    *widget.Select
+	entryClass    *widget.Entry
+	entryExe      *widget.Entry
+	entryCmd      *widget.Entry
+	entryParent   *widget.Entry
+	entryMin      *widget.Entry
+	entryMax      *widget.Entry
+	selectMonitor *widget.Select
+}
+
+// newWindowMatchForm 创建匹配条件表单并填充当前值
+func newWindowMatchForm(m *config.WindowMatch) *windowMatchForm {
+	if m == nil {
+		m = &config.WindowMatch{}
+	}
+	newEntry := func(placeholder, text string) *widget.Entry {
+		e := widget.NewEntry()
+		e.PlaceHolder = placeholder
+		e.SetText(text)
+		return e
+	}
+	f := &windowMatchForm{
+		selectMode:  widget.NewSelect([]string{constants.TextMatchAll, constants.TextMatchAny}, nil),
+		entryClass:  newEntry(constants.PlaceholderMatchClass, m.Class),
+		entryExe:    newEntry(constants.PlaceholderMatchExe, m.ExePath),
+		entryCmd:    newEntry(constants.PlaceholderMatchCmd, m.CmdLine),
+		entryParent: newEntry(constants.PlaceholderMatchParent, m.Parent),
+		entryMin:    newEntry(constants.PlaceholderMatchMin, formatSize(m.MinWidth, m.MinHeight)),
+		entryMax:    newEntry(constants.PlaceholderMatchMax, formatSize(m.MaxWidth, m.MaxHeight)),
+	}
+	f.selectMode.SetSelectedIndex(0)
+	if strings.EqualFold(m.Mode, config.MatchAny) {
+		f.selectMode.SetSelectedIndex(1)
+	}
+	// 显示器选项：第 0 项为“任意显示器”
+	opts := monitorOptions(constants.TextMonitorAny)
+	f.selectMonitor = widget.NewSelect(opts, nil)
+	if m.Monitor >= 0 && m.Monitor < len(opts) {
+		f.selectMonitor.SetSelectedIndex(m.Monitor)
+	} else {
+		f.selectMonitor.SetSelectedIndex(0)
+	}
+
+	f.Container = container.NewVBox(
+		widget.NewLabel(constants.TextMatchTitle),
+		f.selectMode,
+		f.entryClass,
+		f.entryExe,
+		f.entryCmd,
+		f.entryParent,
+		container.NewGridWithColumns(2, f.entryMin, f.entryMax),
+		f.selectMonitor,
+	)
+	return f
+}
+
+// Values 返回表单值；未配置任何条件时返回 nil 以保持旧配置兼容
+func (f *windowMatchForm) Values() (*config.WindowMatch, error) {
+	m := &config.WindowMatch{
+		Class:   strings.TrimSpace(f.entryClass.Text),
+		ExePath: strings.TrimSpace(f.entryExe.Text),
+		CmdLine: strings.TrimSpace(f.entryCmd.Text),
+		Parent:  strings.TrimSpace(f.entryParent.Text),
+	}
+	if f.selectMode.SelectedIndex() == 1 {
+		m.Mode = config.MatchAny
+	}
+	var err error
+	if m.MinWidth, m.MinHeight, err = parseSize(f.entryMin.Text); err != nil {
+		return nil, err
+	}
+	if m.MaxWidth, m.MaxHeight, err = parseSize(f.entryMax.Text); err != nil {
+		return nil, err
+	}
+	if i := f.selectMonitor.SelectedIndex(); i > 0 {
+		m.Monitor = i
+	}
+	// 正则条件使用规则引擎校验
+	for _, p := range []string{m.Class, m.ExePath, m.CmdLine, m.Parent} {
+		if p == "" {
+			continue
+		}
+		if err := appctrl.ValidatePattern(p); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	if *m == (config.WindowMatch{Mode: m.Mode}) {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// formatSize 将尺寸格式化为 “宽x高”；均为 0 时返回空
+func formatSize(w, h int) string {
+	if w == 0 && h == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", w, h)
+}
+
+// parseSize 解析 “宽x高” 格式的尺寸文本；空文本表示不限，单侧为 0 表示该方向不限
+func parseSize(s string) (int, int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, 0, nil
+	}
+	parts := strings.Split(strings.ToLower(s), "x")
+	if len(parts) != 2 {
+		return 0, 0, errors.New(constants.TextMatchSizeError)
+	}
+	w, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || w < 0 {
+		return 0, 0, errors.New(constants.TextMatchSizeError)
+	}
+	h, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || h < 0 {
+		return 0, 0, errors.New(constants.TextMatchSizeError)
+	}
+	return w, h, nil
+}
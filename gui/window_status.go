@@ -1,8 +1,8 @@
 package gui
 
 import (
+	appctrl "cron-shot/app"
 	"cron-shot/constants"
-	"regexp"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -11,10 +11,10 @@ import (
 
 // WindowStatusUI 组件
 type WindowStatusUI struct {
-	Container     *fyne.Container
-	WindowList    *widget.List
-	Windows       []string
-	RulesProvider func() []WindowRule
+	Container  *fyne.Container
+	WindowList *widget.List
+	Windows    []string
+	RuleSet    func() *appctrl.RuleSet
 }
 
 // NewWindowStatusUI 创建进程窗口状态部分的UI
@@ -38,22 +38,9 @@ func NewWindowStatusUI() *WindowStatusUI {
 			if i < len(ui.Windows) {
 				name := ui.Windows[i]
 				hl := false
-				// 正则匹配
-				if ui.RulesProvider != nil {
-					for _, r := range ui.RulesProvider() {
-						if !r.Enabled {
-							continue
-						}
-						if r.Pattern == name {
-							hl = true
-							break
-						}
-						re, err := regexp.Compile(r.Pattern)
-						if err == nil && re.MatchString(name) {
-							hl = true
-							break
-						}
-					}
+				// 使用与自动截图共用的已编译规则集合（优先级、排除规则）
+				if ui.RuleSet != nil {
+					_, hl = ui.RuleSet().Match(name)
 				}
 				lbl := o.(*HoverLabel)
 				lbl.SetText(name)
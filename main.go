@@ -1,11 +1,46 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"cron-shot/cli"
+	"cron-shot/config"
 	"cron-shot/gui"
 	"cron-shot/logging"
+	"cron-shot/sys_utils"
 )
 
 func main() {
 	defer logging.RecoverPanic("main")
-	gui.Run()
+	// 带参数启动时附加到父控制台，使命令行输出与参数错误可见
+	if len(os.Args) > 1 {
+		_ = sys_utils.AttachParentConsole()
+	}
+	g, args, err := cli.ParseGlobal(os.Args[1:], os.Stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	if g.ConfigDir != "" {
+		config.SetBaseDir(g.ConfigDir)
+	}
+	// 配置项覆盖：环境变量 CRONSHOT_* 在前，命令行参数在后（后者优先）
+	overrides, envErr := config.EnvOverrides(os.Environ())
+	if envErr != nil {
+		fmt.Fprintf(os.Stderr, "警告: %v\n", envErr)
+	}
+	config.Init(append(overrides, g.Overrides...)...)
+	store := config.Default()
+	// 带子命令启动时作为命令行工具运行，不创建界面
+	if len(args) > 0 {
+		code := cli.Run(store, args, os.Stdout, os.Stderr)
+		_ = store.Flush()
+		os.Exit(code)
+	}
+	gui.Run(store)
 }
@@ -4,6 +4,7 @@ import (
 	"cron-shot/config"
 	"cron-shot/constants"
 	"cron-shot/sys_utils"
+	"errors"
 	"os"
 	"strings"
 )
@@ -11,16 +12,16 @@ import (
 // InitAutostartRegistration 根据配置同步应用的开机自启注册状态
 // - 开启时：若未注册或注册路径与当前可执行文件不一致，则重新写入注册表
 // - 关闭时：若已注册则移除注册表项
-func InitAutostartRegistration() {
+func InitAutostartRegistration(cfg config.Provider) {
 	app := constants.TextAppTitle
-	if config.GetAutostartEnabled() {
-		exe, _ := os.Executable()
-		// 查询是否已注册以及注册值（可执行路径）
+	if cfg.Snapshot().AutostartEnabled {
+		cmd := autostartCommand()
+		// 查询是否已注册以及注册值（启动命令）
 		ok, v, err := sys_utils.IsAutoStartRegistered(app)
 		if err == nil {
-			// 未注册或路径不一致时，写入当前 exe 路径
-			if !ok || strings.TrimSpace(v) != exe {
-				_ = sys_utils.EnableAutoStart(app, exe)
+			// 未注册或命令不一致时，写入当前启动命令
+			if !ok || strings.TrimSpace(v) != cmd {
+				_ = sys_utils.EnableAutoStart(app, cmd)
 			}
 		}
 	} else {
@@ -31,3 +32,40 @@ func InitAutostartRegistration() {
 		}
 	}
 }
+
+// WatchAutostart 开机自启设置变化（设置窗口、命令行或外部修改配置文件）时同步注册状态
+// 注册失败时调用 onError（不支持自启的平台不报告）；返回取消订阅的函数
+func WatchAutostart(cfg config.Provider, onError func(error)) (cancel func()) {
+	return config.OnChange(cfg, config.ChangeStartup, func(_ config.Change, old, cur config.AppConfig) {
+		if old.AutostartEnabled == cur.AutostartEnabled {
+			return
+		}
+		var err error
+		if cur.AutostartEnabled {
+			err = sys_utils.EnableAutoStart(constants.TextAppTitle, autostartCommand())
+		} else {
+			err = sys_utils.DisableAutoStart(constants.TextAppTitle)
+		}
+		if err != nil && !errors.Is(err, errors.ErrUnsupported) && onError != nil {
+			onError(err)
+		}
+	})
+}
+
+// autostartCommand 返回注册到开机自启的启动命令：当前可执行文件路径；
+// 配置目录来自 --config-dir 时附加该参数，使自启的实例加载同一份配置
+func autostartCommand() string {
+	exe, _ := os.Executable()
+	if config.BaseDirSource() != config.DirSourceFlag {
+		return exe
+	}
+	return quoteArg(exe) + " --config-dir " + quoteArg(config.BaseDir())
+}
+
+// quoteArg 为命令行参数加引号；结尾的反斜杠加倍，避免转义右引号（如 "D:\"）
+func quoteArg(s string) string {
+	if strings.HasSuffix(s, `\`) {
+		s += `\`
+	}
+	return `"` + s + `"`
+}
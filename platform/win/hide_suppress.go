@@ -0,0 +1,19 @@
+package win
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var hideSuppressUntil int64
+
+// SuppressHideFor 在一段时间内抑制自动隐藏
+// 用于避免“显示窗口”后立即被最小化逻辑再次隐藏
+func SuppressHideFor(d time.Duration) {
+	atomic.StoreInt64(&hideSuppressUntil, time.Now().Add(d).UnixNano())
+}
+
+// IsHideSuppressed 返回当前是否处于隐藏抑制期
+func IsHideSuppressed() bool {
+	return time.Now().UnixNano() < atomic.LoadInt64(&hideSuppressUntil)
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package win
+
+import "fyne.io/fyne/v2"
+
+// StartHideOnMinimize 非 Windows 平台无法通过窗口句柄检测最小化，保持窗口管理器的默认行为
+func StartHideOnMinimize(myWindow fyne.Window) {}
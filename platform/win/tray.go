@@ -15,6 +15,19 @@ import (
 // Stoppable 抽象出可停止的后台控制器，用于退出时优雅停止
 type Stoppable interface{ Stop() }
 
+// TrayGroup 托盘菜单中的规则组项
+type TrayGroup struct {
+	Name    string
+	Enabled bool
+}
+
+var (
+	trayDesktop desktop.App
+	trayItems   []*fyne.MenuItem // 固定菜单项（显示/退出）
+	trayGroups  *fyne.MenuItem   // 规则组子菜单（无规则组时为空）
+	trayProfile *fyne.MenuItem   // 配置方案子菜单
+)
+
 // SetupSystemTray 初始化系统托盘菜单（显示/退出）并绑定操作
 func SetupSystemTray(myApp fyne.App, myWindow fyne.Window, stopper Stoppable) {
 	if d, ok := myApp.(desktop.App); ok {
@@ -32,13 +45,85 @@ func SetupSystemTray(myApp fyne.App, myWindow fyne.Window, stopper Stoppable) {
 			fynetooltip.DestroyWindowToolTipLayer(myWindow.Canvas())
 			fyne.CurrentApp().Quit()
 		})
-		d.SetSystemTrayMenu(fyne.NewMenu(constants.TextAppTitle, showItem, exitItem))
+		trayDesktop = d
+		trayItems = []*fyne.MenuItem{showItem, exitItem}
+		refreshTrayMenu()
 		// 设置托盘悬停提示与标题（Windows/Mac 支持悬停提示文本显示）
 		systray.SetTitle(constants.TextAppTitle)
 		systray.SetTooltip(constants.TextAppTitle)
 	}
 }
 
+// SetTrayGroups 更新托盘中的规则组子菜单；勾选项表示手动启用，点击时回调 onToggle
+func SetTrayGroups(groups []TrayGroup, onToggle func(name string, enabled bool)) {
+	trayGroups = nil
+	if len(groups) > 0 {
+		items := make([]*fyne.MenuItem, 0, len(groups))
+		for _, g := range groups {
+			g := g
+			item := fyne.NewMenuItem(g.Name, func() {
+				if onToggle != nil {
+					onToggle(g.Name, !g.Enabled)
+				}
+			})
+			item.Checked = g.Enabled
+			items = append(items, item)
+		}
+		trayGroups = fyne.NewMenuItem(constants.TextRuleGroups, nil)
+		trayGroups.ChildMenu = fyne.NewMenu("", items...)
+	}
+	refreshTrayMenu()
+}
+
+// SetTrayProfiles 更新托盘中的配置方案子菜单：勾选项为当前方案，点击其它方案时回调 onSwitch；
+// 末尾的“新建配置方案…”回调 onCreate
+func SetTrayProfiles(names []string, active string, onSwitch func(name string), onCreate func()) {
+	items := make([]*fyne.MenuItem, 0, len(names)+2)
+	for _, n := range names {
+		n := n
+		item := fyne.NewMenuItem(n, func() {
+			if onSwitch != nil && n != active {
+				onSwitch(n)
+			}
+		})
+		item.Checked = n == active
+		items = append(items, item)
+	}
+	items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem(constants.TextProfileCreate, func() {
+		if onCreate != nil {
+			onCreate()
+		}
+	}))
+	trayProfile = fyne.NewMenuItem(constants.TextProfiles, nil)
+	trayProfile.ChildMenu = fyne.NewMenu("", items...)
+	refreshTrayMenu()
+}
+
+// refreshTrayMenu 重新设置托盘菜单：配置方案与规则组子菜单位于“显示”与“退出”之间
+func refreshTrayMenu() {
+	if trayDesktop == nil || len(trayItems) == 0 {
+		return
+	}
+	items := []*fyne.MenuItem{trayItems[0]}
+	if trayProfile != nil {
+		items = append(items, trayProfile)
+	}
+	if trayGroups != nil {
+		items = append(items, trayGroups)
+	}
+	items = append(items, trayItems[1:]...)
+	trayDesktop.SetSystemTrayMenu(fyne.NewMenu(constants.TextAppTitle, items...))
+}
+
+// SetTrayStatus 更新托盘悬停提示；status 为空时仅显示应用名
+func SetTrayStatus(status string) {
+	tip := constants.TextAppTitle
+	if status != "" {
+		tip += " - " + status
+	}
+	systray.SetTooltip(tip)
+}
+
 // GetTrayIconResource 返回托盘/窗口图标资源（embed.FS 内置）
 func GetTrayIconResource() fyne.Resource {
 	b, _ := assets.Files.ReadFile("icons/cat.png")
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package sys_utils
+
+import "errors"
+
+// EnableAutoStart 非 Windows 平台暂不支持开机自启动
+func EnableAutoStart(appName, exePath string) error { return errors.ErrUnsupported }
+
+// DisableAutoStart 非 Windows 平台暂不支持开机自启动
+func DisableAutoStart(appName string) error { return errors.ErrUnsupported }
+
+// IsAutoStartRegistered 非 Windows 平台暂不支持开机自启动
+func IsAutoStartRegistered(appName string) (bool, string, error) {
+	return false, "", errors.ErrUnsupported
+}
@@ -0,0 +1,201 @@
+package sys_utils
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"strings"
+	"sync"
+)
+
+// WindowInfo 描述一个顶级窗口：标题、平台窗口句柄与所属进程
+// Handle 在 Windows 下为 HWND，在 X11 下为窗口 ID
+type WindowInfo struct {
+	Title   string
+	Handle  uintptr
+	PID     uint32
+	Process string
+}
+
+// Monitor 描述一个显示器在虚拟桌面坐标系中的位置与尺寸
+type Monitor struct {
+	Index   int
+	Name    string
+	Bounds  image.Rectangle
+	Primary bool
+}
+
+// CaptureBackend 抽象平台相关的截图能力
+// 包含进程窗口枚举、单窗口截图、显示器枚举与任意桌面区域截图
+type CaptureBackend interface {
+	ListWindows(processName string) ([]WindowInfo, error)
+	WindowCapturable(w WindowInfo) bool
+	WindowBounds(w WindowInfo) image.Rectangle
+	CaptureWindow(w WindowInfo) (*image.RGBA, error)
+	Monitors() ([]Monitor, error)
+	CaptureRect(r image.Rectangle) (*image.RGBA, error)
+}
+
+// CaptureMetricsReporter 由支持统计的后端实现，返回截图策略统计摘要
+type CaptureMetricsReporter interface {
+	MetricsSummary() string
+}
+
+// AlternateCapturer 由支持多种窗口截图方式的后端实现
+// 在上一结果被判定为空白帧时，换用其它方式重新截图
+type AlternateCapturer interface {
+	CaptureWindowAlternate(w WindowInfo, isBlank func(*image.RGBA) bool) (*image.RGBA, error)
+}
+
+// CaptureMeta 描述一次窗口截图的元数据
+type CaptureMeta struct {
+	DPI      uint32
+	Scale    float64
+	Bounds   image.Rectangle
+	Strategy string
+}
+
+// CaptureMetaProvider 由能够提供窗口 DPI/边框信息的后端实现
+type CaptureMetaProvider interface {
+	CaptureMeta(w WindowInfo) CaptureMeta
+}
+
+var (
+	backendMu        sync.Mutex
+	backendFactories = map[string]func() (CaptureBackend, error){}
+	backendOrder     []string
+	defaultBackend   CaptureBackend
+)
+
+// RegisterCaptureBackend 注册截图后端工厂；由各平台实现在 init 中调用
+// 自动选择时按注册顺序尝试
+func RegisterCaptureBackend(name string, factory func() (CaptureBackend, error)) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	if _, ok := backendFactories[name]; !ok {
+		backendOrder = append(backendOrder, name)
+	}
+	backendFactories[name] = factory
+}
+
+// CaptureBackendNames 返回当前平台已注册的后端名称
+func CaptureBackendNames() []string {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	return append([]string(nil), backendOrder...)
+}
+
+// NewCaptureBackend 按名称创建截图后端；name 为空时返回第一个可用的后端
+func NewCaptureBackend(name string) (CaptureBackend, error) {
+	backendMu.Lock()
+	order := append([]string(nil), backendOrder...)
+	factories := backendFactories
+	backendMu.Unlock()
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name != "" {
+		f, ok := factories[name]
+		if !ok {
+			return nil, fmt.Errorf("capture backend %q not available on this platform", name)
+		}
+		return f()
+	}
+	var lastErr error = errors.New("no capture backend available on this platform")
+	for _, n := range order {
+		b, err := factories[n]()
+		if err == nil {
+			return b, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// SelectCaptureBackend 按名称设置默认截图后端（name 为空表示自动选择）
+func SelectCaptureBackend(name string) error {
+	b, err := NewCaptureBackend(name)
+	if err != nil {
+		return err
+	}
+	backendMu.Lock()
+	defaultBackend = b
+	backendMu.Unlock()
+	return nil
+}
+
+// DefaultCaptureBackend 返回默认截图后端；未选择时自动选择
+// 没有可用后端时返回的实现对所有调用均返回错误
+func DefaultCaptureBackend() CaptureBackend {
+	backendMu.Lock()
+	b := defaultBackend
+	backendMu.Unlock()
+	if b != nil {
+		return b
+	}
+	b, err := NewCaptureBackend("")
+	if err != nil {
+		b = unavailableBackend{err: err}
+	}
+	backendMu.Lock()
+	defaultBackend = b
+	backendMu.Unlock()
+	return b
+}
+
+// unavailableBackend 在没有可用后端（如无图形会话）时占位
+type unavailableBackend struct{ err error }
+
+func (u unavailableBackend) ListWindows(string) ([]WindowInfo, error)         { return nil, u.err }
+func (u unavailableBackend) WindowCapturable(WindowInfo) bool                 { return false }
+func (u unavailableBackend) WindowBounds(WindowInfo) image.Rectangle          { return image.Rectangle{} }
+func (u unavailableBackend) CaptureWindow(WindowInfo) (*image.RGBA, error)    { return nil, u.err }
+func (u unavailableBackend) Monitors() ([]Monitor, error)                     { return nil, u.err }
+func (u unavailableBackend) CaptureRect(image.Rectangle) (*image.RGBA, error) { return nil, u.err }
+
+// CaptureMonitor 截取指定序号的显示器
+func CaptureMonitor(b CaptureBackend, index int) (*image.RGBA, error) {
+	mons, err := b.Monitors()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(mons) {
+		return nil, fmt.Errorf("monitor %d not found (%d available)", index, len(mons))
+	}
+	return b.CaptureRect(mons[index].Bounds)
+}
+
+// CaptureAllMonitors 逐个截取所有显示器并按其桌面坐标拼接为一张图
+// 显示器之间的空隙保持透明
+func CaptureAllMonitors(b CaptureBackend) (*image.RGBA, error) {
+	mons, err := b.Monitors()
+	if err != nil {
+		return nil, err
+	}
+	if len(mons) == 0 {
+		return nil, errors.New("no monitors found")
+	}
+	union := mons[0].Bounds
+	for _, m := range mons[1:] {
+		union = union.Union(m.Bounds)
+	}
+	out := image.NewRGBA(image.Rect(0, 0, union.Dx(), union.Dy()))
+	for _, m := range mons {
+		img, err := b.CaptureRect(m.Bounds)
+		if err != nil {
+			return nil, err
+		}
+		dst := m.Bounds.Sub(union.Min)
+		draw.Draw(out, dst, img, img.Bounds().Min, draw.Src)
+	}
+	return out, nil
+}
+
+// MonitorIndexAt 返回包含给定点的显示器序号；未命中时返回 -1
+func MonitorIndexAt(mons []Monitor, p image.Point) int {
+	for _, m := range mons {
+		if p.In(m.Bounds) {
+			return m.Index
+		}
+	}
+	return -1
+}
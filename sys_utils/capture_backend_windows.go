@@ -0,0 +1,164 @@
+package sys_utils
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"cron-shot/logging"
+	"cron-shot/utils"
+
+	"github.com/lxn/win"
+)
+
+// BackendWin32 Win32 截图后端名称
+const BackendWin32 = "win32"
+
+// BackendWin32DWM Win32 截图后端，其它方式均失败时再尝试 DWM 缩略图（截图时屏幕上会短暂出现宿主窗口）
+const BackendWin32DWM = "win32_dwm"
+
+func init() {
+	RegisterCaptureBackend(BackendWin32, func() (CaptureBackend, error) { return win32Backend{chain: windowCaptureChain}, nil })
+	RegisterCaptureBackend(BackendWin32DWM, func() (CaptureBackend, error) { return win32Backend{chain: windowCaptureChainDWM}, nil })
+}
+
+// win32Backend 基于 Win32 API 的截图后端；chain 为窗口截图使用的策略链
+type win32Backend struct {
+	chain *CaptureChain
+}
+
+func (win32Backend) ListWindows(processName string) ([]WindowInfo, error) {
+	return GetProcessWindowsDetailed(processName)
+}
+
+// WindowCapturable 跳过最小化或不可见窗口，避免空白截图
+func (win32Backend) WindowCapturable(w WindowInfo) bool {
+	return !win.IsIconic(w.hwnd()) && win.IsWindowVisible(w.hwnd())
+}
+
+func (win32Backend) WindowBounds(w WindowInfo) image.Rectangle {
+	return GetWindowBounds(w.hwnd())
+}
+
+// CaptureWindow 通过策略链截图，并按 w.Process 记住可用的策略
+func (b win32Backend) CaptureWindow(w WindowInfo) (*image.RGBA, error) {
+	img, _, err := b.chain.Capture(w)
+	return img, err
+}
+
+func (b win32Backend) CaptureWindowAlternate(w WindowInfo, isBlank func(*image.RGBA) bool) (*image.RGBA, error) {
+	img, _, err := b.chain.CaptureAlternate(w, isBlank)
+	return img, err
+}
+
+// CaptureMeta 返回窗口截图的元数据：DPI 与缩放比例、可见边框与最近生效的截图策略
+func (b win32Backend) CaptureMeta(w WindowInfo) CaptureMeta {
+	dpi := GetWindowDPI(w.hwnd())
+	return CaptureMeta{
+		DPI:      dpi,
+		Scale:    utils.DPIScale(dpi),
+		Bounds:   GetWindowBounds(w.hwnd()),
+		Strategy: b.chain.PreferredStrategy(w.Process),
+	}
+}
+
+// WindowClass 返回窗口类名（GetClassNameW）
+func (win32Backend) WindowClass(w WindowInfo) string {
+	var buf [256]uint16
+	n, err := win.GetClassName(w.hwnd(), &buf[0], len(buf))
+	if err != nil || n == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf[:n])
+}
+
+func (b win32Backend) MetricsSummary() string { return b.chain.MetricsSummary() }
+
+func (win32Backend) Monitors() ([]Monitor, error) { return EnumMonitors() }
+
+func (win32Backend) CaptureRect(r image.Rectangle) (*image.RGBA, error) {
+	return CaptureScreenRect(r)
+}
+
+// CaptureScreenRect 从屏幕 DC 复制指定的虚拟桌面区域
+func CaptureScreenRect(r image.Rectangle) (*image.RGBA, error) {
+	width, height := r.Dx(), r.Dy()
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("empty capture rectangle")
+	}
+	hdcScreen := win.GetDC(0)
+	defer win.ReleaseDC(0, hdcScreen)
+	hdcMem := win.CreateCompatibleDC(hdcScreen)
+	defer win.DeleteDC(hdcMem)
+	hbm := win.CreateCompatibleBitmap(hdcScreen, int32(width), int32(height))
+	defer win.DeleteObject(win.HGDIOBJ(hbm))
+	win.SelectObject(hdcMem, win.HGDIOBJ(hbm))
+	if !win.BitBlt(hdcMem, 0, 0, int32(width), int32(height), hdcScreen, int32(r.Min.X), int32(r.Min.Y), win.SRCCOPY|win.CAPTUREBLT) {
+		return nil, errors.New("BitBlt failed")
+	}
+	return bitmapToRGBA(hdcMem, hbm, width, height), nil
+}
+
+// monitorInfoEx 对应 MONITORINFOEXW，额外包含设备名
+type monitorInfoEx struct {
+	win.MONITORINFO
+	SzDevice [32]uint16
+}
+
+// 说明：与窗口枚举相同，使用单例回调与包级上下文收集结果
+var (
+	procEnumDisplayMonitors = user32.NewProc("EnumDisplayMonitors")
+	procGetMonitorInfoW     = user32.NewProc("GetMonitorInfoW")
+	enumMonOnce             sync.Once
+	enumMonCB               uintptr
+	enumMonMu               sync.Mutex
+	enumMonOut              *[]Monitor
+)
+
+// EnumMonitors 枚举所有显示器，按系统枚举顺序编号
+func EnumMonitors() ([]Monitor, error) {
+	enumMonOnce.Do(func() {
+		enumMonCB = syscall.NewCallback(enumMonitorCallback)
+	})
+	out := make([]Monitor, 0)
+	enumMonMu.Lock()
+	defer enumMonMu.Unlock()
+	enumMonOut = &out
+	r, _, _ := procEnumDisplayMonitors.Call(0, 0, enumMonCB, 0)
+	enumMonOut = nil
+	if r == 0 {
+		return nil, errors.New("EnumDisplayMonitors failed")
+	}
+	return out, nil
+}
+
+// enumMonitorCallback 读取显示器信息并追加到结果
+func enumMonitorCallback(hMonitor win.HMONITOR, hdc win.HDC, rc *win.RECT, lParam uintptr) uintptr {
+	defer logging.RecoverPanic("enumMonitorCallback")
+	var mi monitorInfoEx
+	mi.CbSize = uint32(unsafe.Sizeof(mi))
+	r, _, _ := procGetMonitorInfoW.Call(uintptr(hMonitor), uintptr(unsafe.Pointer(&mi)))
+	if r == 0 || enumMonOut == nil {
+		return 1
+	}
+	const MONITORINFOF_PRIMARY = 0x1
+	rm := mi.RcMonitor
+	*enumMonOut = append(*enumMonOut, Monitor{
+		Index:   len(*enumMonOut),
+		Name:    syscall.UTF16ToString(mi.SzDevice[:]),
+		Bounds:  image.Rect(int(rm.Left), int(rm.Top), int(rm.Right), int(rm.Bottom)),
+		Primary: mi.DwFlags&MONITORINFOF_PRIMARY != 0,
+	})
+	return 1
+}
+
+// GetWindowBounds 返回窗口在虚拟桌面中的可见矩形（物理像素）
+// 优先使用 DWM 扩展边框，排除 GetWindowRect 中不可见的阴影边框
+func GetWindowBounds(hwnd win.HWND) image.Rectangle {
+	if b, ok := getExtendedFrameBounds(hwnd); ok {
+		return b
+	}
+	return getWindowRect(hwnd)
+}
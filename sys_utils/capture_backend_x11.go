@@ -0,0 +1,379 @@
+//go:build linux
+
+package sys_utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/composite"
+	"github.com/jezek/xgb/randr"
+	"github.com/jezek/xgb/xproto"
+)
+
+// BackendX11 X11 截图后端名称
+const BackendX11 = "x11"
+
+func init() {
+	RegisterCaptureBackend(BackendX11, sharedX11Backend)
+}
+
+// 每个进程只保持一个 X 连接：切换截图后端时重复调用工厂函数不会建立新连接，
+// 旧后端可能仍在截图，因此也不关闭连接
+var (
+	x11Mu     sync.Mutex
+	x11Shared *x11Backend
+)
+
+// sharedX11Backend 返回进程内共享的 X11 后端；连接失败时不缓存，下次调用重试
+func sharedX11Backend() (CaptureBackend, error) {
+	x11Mu.Lock()
+	defer x11Mu.Unlock()
+	if x11Shared == nil {
+		b, err := newX11Backend()
+		if err != nil {
+			return nil, err
+		}
+		x11Shared = b
+	}
+	return x11Shared, nil
+}
+
+// x11Backend 基于 X11 协议的截图后端（纯 Go 实现，连接 $DISPLAY）
+// 窗口枚举读取 _NET_CLIENT_LIST，标题优先 _NET_WM_NAME，进程通过 _NET_WM_PID 与 /proc 解析；
+// 截图依次尝试 XComposite 离屏像素图、窗口 XGetImage 与根窗口区域 XGetImage
+type x11Backend struct {
+	conn      *xgb.Conn
+	root      xproto.Window
+	rootSize  image.Rectangle
+	bpp       map[byte]byte // 深度 → 每像素位数
+	lsbFirst  bool
+	composite bool
+	randr     bool
+	atomsMu   sync.Mutex
+	atoms     map[string]xproto.Atom
+	chain     *CaptureChain
+}
+
+// newX11Backend 连接 X 服务器并初始化扩展
+func newX11Backend() (*x11Backend, error) {
+	if os.Getenv("DISPLAY") == "" {
+		return nil, errors.New("DISPLAY is not set")
+	}
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, err
+	}
+	setup := xproto.Setup(conn)
+	screen := setup.DefaultScreen(conn)
+	b := &x11Backend{
+		conn:     conn,
+		root:     screen.Root,
+		rootSize: image.Rect(0, 0, int(screen.WidthInPixels), int(screen.HeightInPixels)),
+		bpp:      map[byte]byte{},
+		lsbFirst: setup.ImageByteOrder == xproto.ImageOrderLSBFirst,
+		atoms:    map[string]xproto.Atom{},
+	}
+	for _, f := range setup.PixmapFormats {
+		b.bpp[f.Depth] = f.BitsPerPixel
+	}
+	b.composite = composite.Init(conn) == nil
+	b.randr = randr.Init(conn) == nil
+	b.chain = NewCaptureChain(
+		CaptureStrategy{Name: "composite", Capture: b.captureComposite},
+		CaptureStrategy{Name: "getimage", Capture: b.captureWindowDirect},
+		CaptureStrategy{Name: "root_region", Capture: func(w WindowInfo) (*image.RGBA, error) {
+			return b.CaptureRect(b.WindowBounds(w))
+		}},
+	)
+	return b, nil
+}
+
+// atom 返回（并缓存）指定名称的 Atom
+func (b *x11Backend) atom(name string) xproto.Atom {
+	b.atomsMu.Lock()
+	defer b.atomsMu.Unlock()
+	if a, ok := b.atoms[name]; ok {
+		return a
+	}
+	r, err := xproto.InternAtom(b.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return xproto.AtomNone
+	}
+	b.atoms[name] = r.Atom
+	return r.Atom
+}
+
+// property 读取窗口属性的原始值
+func (b *x11Backend) property(w xproto.Window, name string) (*xproto.GetPropertyReply, error) {
+	r, err := xproto.GetProperty(b.conn, false, w, b.atom(name), xproto.AtomAny, 0, 1<<16).Reply()
+	if err != nil {
+		return nil, err
+	}
+	if r.Format == 0 {
+		return nil, fmt.Errorf("property %s not set", name)
+	}
+	return r, nil
+}
+
+// propertyUint32s 将 32 位格式的属性解析为整数列表
+func (b *x11Backend) propertyUint32s(w xproto.Window, name string) []uint32 {
+	r, err := b.property(w, name)
+	if err != nil || r.Format != 32 {
+		return nil
+	}
+	out := make([]uint32, 0, r.ValueLen)
+	for i := 0; i+4 <= len(r.Value); i += 4 {
+		out = append(out, xgb.Get32(r.Value[i:]))
+	}
+	return out
+}
+
+// clientWindows 返回窗口管理器维护的顶级窗口列表；不支持 EWMH 时退回根窗口子窗口
+func (b *x11Backend) clientWindows() ([]xproto.Window, error) {
+	if ids := b.propertyUint32s(b.root, "_NET_CLIENT_LIST"); len(ids) > 0 {
+		out := make([]xproto.Window, 0, len(ids))
+		for _, id := range ids {
+			out = append(out, xproto.Window(id))
+		}
+		return out, nil
+	}
+	tree, err := xproto.QueryTree(b.conn, b.root).Reply()
+	if err != nil {
+		return nil, err
+	}
+	return tree.Children, nil
+}
+
+// windowTitle 优先读取 UTF-8 的 _NET_WM_NAME，其次 WM_NAME
+func (b *x11Backend) windowTitle(w xproto.Window) string {
+	for _, name := range []string{"_NET_WM_NAME", "WM_NAME"} {
+		if r, err := b.property(w, name); err == nil && r.Format == 8 && len(r.Value) > 0 {
+			return string(r.Value)
+		}
+	}
+	return ""
+}
+
+// WindowClass 返回 WM_CLASS 的类名部分（实例名之后的第二个字符串）
+func (b *x11Backend) WindowClass(w WindowInfo) string {
+	r, err := b.property(xproto.Window(w.Handle), "WM_CLASS")
+	if err != nil || r.Format != 8 {
+		return ""
+	}
+	parts := strings.Split(strings.TrimRight(string(r.Value), "\x00"), "\x00")
+	return parts[len(parts)-1]
+}
+
+// windowPID 读取 _NET_WM_PID；未设置时返回 0
+func (b *x11Backend) windowPID(w xproto.Window) uint32 {
+	if v := b.propertyUint32s(w, "_NET_WM_PID"); len(v) > 0 {
+		return v[0]
+	}
+	return 0
+}
+
+// processNameByPID 通过 /proc 解析进程名：优先可执行文件名，其次 comm（最长 15 字符）
+func processNameByPID(pid uint32) string {
+	base := filepath.Join("/proc", strconv.Itoa(int(pid)))
+	if exe, err := os.Readlink(filepath.Join(base, "exe")); err == nil {
+		return filepath.Base(strings.TrimSuffix(exe, " (deleted)"))
+	}
+	if comm, err := os.ReadFile(filepath.Join(base, "comm")); err == nil {
+		return strings.TrimSpace(string(comm))
+	}
+	return ""
+}
+
+// sameProcessName 比较进程名，忽略大小写与 Windows 的 .exe 后缀，便于在平台间共用配置
+func sameProcessName(a, b string) bool {
+	trim := func(s string) string {
+		s = strings.TrimSpace(s)
+		if strings.HasSuffix(strings.ToLower(s), ".exe") {
+			s = s[:len(s)-4]
+		}
+		return s
+	}
+	return strings.EqualFold(trim(a), trim(b))
+}
+
+func (b *x11Backend) ListWindows(processName string) ([]WindowInfo, error) {
+	wins, err := b.clientWindows()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]WindowInfo, 0)
+	for _, w := range wins {
+		title := b.windowTitle(w)
+		if title == "" {
+			continue
+		}
+		pid := b.windowPID(w)
+		if pid == 0 {
+			continue
+		}
+		name := processNameByPID(pid)
+		if !sameProcessName(name, processName) {
+			continue
+		}
+		out = append(out, WindowInfo{Title: title, Handle: uintptr(w), PID: pid, Process: name})
+	}
+	return out, nil
+}
+
+// WindowCapturable 跳过未映射或被最小化（_NET_WM_STATE_HIDDEN）的窗口
+func (b *x11Backend) WindowCapturable(w WindowInfo) bool {
+	xw := xproto.Window(w.Handle)
+	attrs, err := xproto.GetWindowAttributes(b.conn, xw).Reply()
+	if err != nil || attrs.MapState != xproto.MapStateViewable {
+		return false
+	}
+	hidden := uint32(b.atom("_NET_WM_STATE_HIDDEN"))
+	for _, s := range b.propertyUint32s(xw, "_NET_WM_STATE") {
+		if s == hidden {
+			return false
+		}
+	}
+	return true
+}
+
+// WindowBounds 返回窗口在根窗口坐标系中的矩形（不含窗口管理器装饰）
+func (b *x11Backend) WindowBounds(w WindowInfo) image.Rectangle {
+	xw := xproto.Window(w.Handle)
+	g, err := xproto.GetGeometry(b.conn, xproto.Drawable(xw)).Reply()
+	if err != nil {
+		return image.Rectangle{}
+	}
+	t, err := xproto.TranslateCoordinates(b.conn, xw, b.root, 0, 0).Reply()
+	if err != nil {
+		return image.Rectangle{}
+	}
+	return image.Rect(int(t.DstX), int(t.DstY), int(t.DstX)+int(g.Width), int(t.DstY)+int(g.Height))
+}
+
+// CaptureWindow 通过策略链截图，并按 w.Process 记住可用的策略
+func (b *x11Backend) CaptureWindow(w WindowInfo) (*image.RGBA, error) {
+	img, _, err := b.chain.Capture(w)
+	return img, err
+}
+
+func (b *x11Backend) CaptureWindowAlternate(w WindowInfo, isBlank func(*image.RGBA) bool) (*image.RGBA, error) {
+	img, _, err := b.chain.CaptureAlternate(w, isBlank)
+	return img, err
+}
+
+func (b *x11Backend) MetricsSummary() string { return b.chain.MetricsSummary() }
+
+// CaptureMeta 返回窗口边框与最近生效的截图策略（X11 下不区分 DPI）
+func (b *x11Backend) CaptureMeta(w WindowInfo) CaptureMeta {
+	return CaptureMeta{Bounds: b.WindowBounds(w), Strategy: b.chain.PreferredStrategy(w.Process)}
+}
+
+// captureComposite 读取合成管理器为窗口维护的离屏像素图，窗口被遮挡时仍可截取
+// 没有合成管理器（窗口未重定向）时失败
+func (b *x11Backend) captureComposite(w WindowInfo) (*image.RGBA, error) {
+	if !b.composite {
+		return nil, errors.New("XComposite extension not available")
+	}
+	xw := xproto.Window(w.Handle)
+	pix, err := xproto.NewPixmapId(b.conn)
+	if err != nil {
+		return nil, err
+	}
+	if err := composite.NameWindowPixmapChecked(b.conn, xw, pix).Check(); err != nil {
+		return nil, err
+	}
+	defer xproto.FreePixmap(b.conn, pix)
+	g, err := xproto.GetGeometry(b.conn, xproto.Drawable(pix)).Reply()
+	if err != nil {
+		return nil, err
+	}
+	return b.getImage(xproto.Drawable(pix), image.Rect(0, 0, int(g.Width), int(g.Height)))
+}
+
+// captureWindowDirect 直接对窗口执行 XGetImage；被遮挡部分内容未定义
+func (b *x11Backend) captureWindowDirect(w WindowInfo) (*image.RGBA, error) {
+	xw := xproto.Window(w.Handle)
+	g, err := xproto.GetGeometry(b.conn, xproto.Drawable(xw)).Reply()
+	if err != nil {
+		return nil, err
+	}
+	return b.getImage(xproto.Drawable(xw), image.Rect(0, 0, int(g.Width), int(g.Height)))
+}
+
+// Monitors 通过 RandR 1.5 枚举显示器；不可用时将整个根窗口视为单个显示器
+func (b *x11Backend) Monitors() ([]Monitor, error) {
+	if b.randr {
+		if r, err := randr.GetMonitors(b.conn, b.root, true).Reply(); err == nil && len(r.Monitors) > 0 {
+			out := make([]Monitor, 0, len(r.Monitors))
+			for i, m := range r.Monitors {
+				name := ""
+				if an, err := xproto.GetAtomName(b.conn, m.Name).Reply(); err == nil {
+					name = an.Name
+				}
+				out = append(out, Monitor{
+					Index:   i,
+					Name:    name,
+					Bounds:  image.Rect(int(m.X), int(m.Y), int(m.X)+int(m.Width), int(m.Y)+int(m.Height)),
+					Primary: m.Primary,
+				})
+			}
+			return out, nil
+		}
+	}
+	return []Monitor{{Index: 0, Name: "screen", Bounds: b.rootSize, Primary: true}}, nil
+}
+
+// CaptureRect 从根窗口复制指定区域（超出屏幕的部分被裁剪）
+func (b *x11Backend) CaptureRect(r image.Rectangle) (*image.RGBA, error) {
+	r = r.Intersect(b.rootSize)
+	if r.Empty() {
+		return nil, errors.New("empty capture rectangle")
+	}
+	return b.getImage(xproto.Drawable(b.root), r)
+}
+
+// getImage 以 ZPixmap 格式读取可绘制对象的区域并转换为 RGBA
+// 仅支持 32 位每像素（深度 24/32）的常见 TrueColor 格式
+func (b *x11Backend) getImage(d xproto.Drawable, r image.Rectangle) (*image.RGBA, error) {
+	w, h := r.Dx(), r.Dy()
+	if w <= 0 || h <= 0 {
+		return nil, errors.New("empty capture rectangle")
+	}
+	reply, err := xproto.GetImage(b.conn, xproto.ImageFormatZPixmap, d, int16(r.Min.X), int16(r.Min.Y), uint16(w), uint16(h), 0xffffffff).Reply()
+	if err != nil {
+		return nil, err
+	}
+	if bpp := b.bpp[reply.Depth]; bpp != 32 {
+		return nil, fmt.Errorf("unsupported pixmap format: depth %d, %d bpp", reply.Depth, bpp)
+	}
+	if len(reply.Data) < w*h*4 {
+		return nil, errors.New("short image data")
+	}
+	order := binary.ByteOrder(binary.LittleEndian)
+	if !b.lsbFirst {
+		order = binary.BigEndian
+	}
+	keepAlpha := reply.Depth == 32
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < w*h; i++ {
+		px := order.Uint32(reply.Data[i*4:])
+		a := byte(px >> 24)
+		if !keepAlpha {
+			a = 255
+		}
+		img.Pix[i*4+0] = byte(px >> 16)
+		img.Pix[i*4+1] = byte(px >> 8)
+		img.Pix[i*4+2] = byte(px)
+		img.Pix[i*4+3] = a
+	}
+	return img, nil
+}
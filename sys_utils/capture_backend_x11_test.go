@@ -0,0 +1,164 @@
+//go:build linux
+
+package sys_utils
+
+import (
+	"image"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// 需要 X 服务器，通常在 Xvfb 中运行：xvfb-run go test ./sys_utils/
+// 测试创建一个纯色窗口，检查枚举、属性读取与区域截图
+
+const testWindowTitle = "cron-shot x11 backend test"
+
+// createTestWindow 在 r 处创建并映射红色顶级窗口，设置标题、_NET_WM_PID 与 WM_CLASS
+func createTestWindow(t *testing.T, b *x11Backend, r image.Rectangle) xproto.Window {
+	t.Helper()
+	wid, err := xproto.NewWindowId(b.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	screen := xproto.Setup(b.conn).DefaultScreen(b.conn)
+	err = xproto.CreateWindowChecked(b.conn, screen.RootDepth, wid, b.root,
+		int16(r.Min.X), int16(r.Min.Y), uint16(r.Dx()), uint16(r.Dy()), 0,
+		xproto.WindowClassInputOutput, screen.RootVisual,
+		xproto.CwBackPixel, []uint32{0xff0000}).Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { xproto.DestroyWindow(b.conn, wid) })
+	setProp := func(name string, typ xproto.Atom, format byte, data []byte) {
+		n := uint32(len(data)) / uint32(format/8)
+		if err := xproto.ChangePropertyChecked(b.conn, xproto.PropModeReplace, wid, b.atom(name), typ, format, n, data).Check(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setProp("_NET_WM_NAME", b.atom("UTF8_STRING"), 8, []byte(testWindowTitle))
+	pid := make([]byte, 4)
+	xgb.Put32(pid, uint32(os.Getpid()))
+	setProp("_NET_WM_PID", xproto.AtomCardinal, 32, pid)
+	setProp("WM_CLASS", xproto.AtomString, 8, []byte("cronshot-test\x00CronShotTest\x00"))
+	if err := xproto.MapWindowChecked(b.conn, wid).Check(); err != nil {
+		t.Fatal(err)
+	}
+	xproto.ClearArea(b.conn, false, wid, 0, 0, 0, 0)
+	// 往返一次请求，确保映射与绘制已被服务器处理
+	if _, err := xproto.GetInputFocus(b.conn).Reply(); err != nil {
+		t.Fatal(err)
+	}
+	return wid
+}
+
+func newTestX11Backend(t *testing.T) *x11Backend {
+	t.Helper()
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("DISPLAY is not set; run under Xvfb")
+	}
+	b, err := newX11Backend()
+	if err != nil {
+		t.Fatalf("connect to %s: %v", os.Getenv("DISPLAY"), err)
+	}
+	t.Cleanup(b.conn.Close)
+	return b
+}
+
+func TestX11ListWindows(t *testing.T) {
+	b := newTestX11Backend(t)
+	wid := createTestWindow(t, b, image.Rect(10, 20, 210, 140))
+	proc := processNameByPID(uint32(os.Getpid()))
+	if proc == "" {
+		t.Fatal("cannot resolve the test process name")
+	}
+
+	// 有窗口管理器时 _NET_CLIENT_LIST 异步更新，轮询等待
+	var found *WindowInfo
+	for deadline := time.Now().Add(3 * time.Second); found == nil && time.Now().Before(deadline); {
+		wins, err := b.ListWindows(proc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range wins {
+			if wins[i].Handle == uintptr(wid) {
+				found = &wins[i]
+			}
+		}
+		if found == nil {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	if found == nil {
+		t.Fatalf("window %d not listed for process %q", wid, proc)
+	}
+	if found.Title != testWindowTitle || found.PID != uint32(os.Getpid()) || found.Process != proc {
+		t.Errorf("ListWindows = %+v", *found)
+	}
+	if wins, _ := b.ListWindows(proc + "-other"); len(wins) != 0 {
+		t.Errorf("windows listed for another process: %+v", wins)
+	}
+	if c := b.WindowClass(*found); c != "CronShotTest" {
+		t.Errorf("WindowClass = %q, want CronShotTest", c)
+	}
+	if !b.WindowCapturable(*found) {
+		t.Errorf("mapped window reported as not capturable")
+	}
+	if got := b.WindowBounds(*found); got.Dx() != 200 || got.Dy() != 120 {
+		t.Errorf("WindowBounds = %v, want 200x120", got)
+	}
+}
+
+func TestX11CaptureRect(t *testing.T) {
+	b := newTestX11Backend(t)
+	wid := createTestWindow(t, b, image.Rect(30, 40, 130, 100))
+	bounds := b.WindowBounds(WindowInfo{Handle: uintptr(wid)})
+	if bounds.Empty() {
+		t.Fatal("empty window bounds")
+	}
+
+	img, err := b.CaptureRect(bounds)
+	if err != nil {
+		t.Fatalf("CaptureRect: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, bounds.Dx(), bounds.Dy()) {
+		t.Fatalf("image bounds = %v, want %dx%d", img.Bounds(), bounds.Dx(), bounds.Dy())
+	}
+	if c := img.RGBAAt(bounds.Dx()/2, bounds.Dy()/2); c.R < 200 || c.G > 50 || c.B > 50 || c.A != 255 {
+		t.Errorf("center pixel = %v, want opaque red", c)
+	}
+
+	mons, err := b.Monitors()
+	if err != nil || len(mons) == 0 {
+		t.Fatalf("Monitors = %v, %v", mons, err)
+	}
+	// 超出屏幕的区域被裁剪，完全在屏幕外时报错
+	edge := image.Rect(b.rootSize.Max.X-10, 0, b.rootSize.Max.X+10, 10)
+	if img, err := b.CaptureRect(edge); err != nil {
+		t.Errorf("CaptureRect(%v): %v", edge, err)
+	} else if img.Bounds().Dx() != 10 {
+		t.Errorf("CaptureRect(%v) bounds = %v, want 10px wide", edge, img.Bounds())
+	}
+	if _, err := b.CaptureRect(image.Rect(-20, -20, -10, -10)); err == nil {
+		t.Errorf("CaptureRect outside the screen succeeded")
+	}
+}
+
+// TestX11BackendShared 重复创建 x11 后端（如在界面中切换后端）复用同一连接
+func TestX11BackendShared(t *testing.T) {
+	newTestX11Backend(t)
+	a, err := NewCaptureBackend(BackendX11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewCaptureBackend(BackendX11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.(*x11Backend).conn != b.(*x11Backend).conn {
+		t.Errorf("each NewCaptureBackend call opened a new X connection")
+	}
+}
@@ -0,0 +1,180 @@
+package sys_utils
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+	"sync"
+
+	"cron-shot/logging"
+	"cron-shot/utils"
+)
+
+// ErrBlankCapture 表示截图成功但结果为全黑图像
+var ErrBlankCapture = errors.New("capture result is all black")
+
+// CaptureStrategy 表示一种窗口截图方式
+type CaptureStrategy struct {
+	Name    string
+	Capture func(w WindowInfo) (*image.RGBA, error)
+}
+
+// StrategyMetrics 记录单个截图策略的调用统计
+type StrategyMetrics struct {
+	Attempts  int
+	Successes int
+	Failures  int // 调用返回错误
+	Blank     int // 返回全黑图像
+}
+
+// CaptureChain 按顺序尝试多种截图策略
+// 自动拒绝全黑结果并尝试下一种；按进程记住最近成功的策略，下次优先使用
+type CaptureChain struct {
+	strategies []CaptureStrategy
+	mu         sync.Mutex
+	preferred  map[string]string
+	metrics    map[string]*StrategyMetrics
+}
+
+// NewCaptureChain 创建策略链，strategies 的顺序即默认尝试顺序
+func NewCaptureChain(strategies ...CaptureStrategy) *CaptureChain {
+	return &CaptureChain{
+		strategies: strategies,
+		preferred:  map[string]string{},
+		metrics:    map[string]*StrategyMetrics{},
+	}
+}
+
+// Capture 对窗口执行截图，返回图像与最终生效的策略名
+// 全部策略失败时返回最后一个错误
+func (c *CaptureChain) Capture(w WindowInfo) (*image.RGBA, string, error) {
+	key := strings.ToLower(w.Process)
+	var lastErr error
+	for _, s := range c.ordered(key) {
+		img, err := s.Capture(w)
+		if err == nil && utils.IsAllBlack(img) {
+			err = ErrBlankCapture
+		}
+		c.record(s.Name, err)
+		if err != nil {
+			logging.Info(fmt.Sprintf("capture strategy %s failed for %q: %v", s.Name, w.Title, err))
+			lastErr = err
+			continue
+		}
+		c.mu.Lock()
+		c.preferred[key] = s.Name
+		c.mu.Unlock()
+		return img, s.Name, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no capture strategy available")
+	}
+	return nil, "", lastErr
+}
+
+// CaptureAlternate 跳过该进程最近成功的策略，按默认顺序尝试其余策略
+// isBlank 用于拒绝不合格的结果（为空时仅拒绝全黑图像）；成功后该策略成为进程首选
+func (c *CaptureChain) CaptureAlternate(w WindowInfo, isBlank func(*image.RGBA) bool) (*image.RGBA, string, error) {
+	key := strings.ToLower(w.Process)
+	c.mu.Lock()
+	skip := c.preferred[key]
+	c.mu.Unlock()
+	var lastErr error
+	for _, s := range c.strategies {
+		if s.Name == skip {
+			continue
+		}
+		img, err := s.Capture(w)
+		if err == nil && (utils.IsAllBlack(img) || (isBlank != nil && isBlank(img))) {
+			err = ErrBlankCapture
+		}
+		c.record(s.Name, err)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		c.mu.Lock()
+		c.preferred[key] = s.Name
+		c.mu.Unlock()
+		return img, s.Name, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no alternate capture strategy available")
+	}
+	return nil, "", lastErr
+}
+
+// ordered 返回本次尝试顺序：该进程上次成功的策略排在最前，其余保持默认顺序
+func (c *CaptureChain) ordered(key string) []CaptureStrategy {
+	c.mu.Lock()
+	pref := c.preferred[key]
+	c.mu.Unlock()
+	out := make([]CaptureStrategy, 0, len(c.strategies))
+	for _, s := range c.strategies {
+		if s.Name == pref {
+			out = append(out, s)
+		}
+	}
+	for _, s := range c.strategies {
+		if s.Name != pref {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// record 累加策略统计
+func (c *CaptureChain) record(name string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.metrics[name]
+	if m == nil {
+		m = &StrategyMetrics{}
+		c.metrics[name] = m
+	}
+	m.Attempts++
+	switch {
+	case err == nil:
+		m.Successes++
+	case errors.Is(err, ErrBlankCapture):
+		m.Blank++
+	default:
+		m.Failures++
+	}
+}
+
+// Metrics 返回各策略统计的快照
+func (c *CaptureChain) Metrics() map[string]StrategyMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]StrategyMetrics, len(c.metrics))
+	for k, v := range c.metrics {
+		out[k] = *v
+	}
+	return out
+}
+
+// PreferredStrategy 返回进程最近一次成功使用的策略名
+func (c *CaptureChain) PreferredStrategy(processName string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.preferred[strings.ToLower(processName)]
+}
+
+// MetricsSummary 将统计格式化为单行文本，便于写入日志
+func (c *CaptureChain) MetricsSummary() string {
+	m := c.Metrics()
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, n := range names {
+		v := m[n]
+		parts = append(parts, fmt.Sprintf("%s: attempts=%d ok=%d failed=%d blank=%d", n, v.Attempts, v.Successes, v.Failures, v.Blank))
+	}
+	return strings.Join(parts, "; ")
+}
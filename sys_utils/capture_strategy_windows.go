@@ -0,0 +1,157 @@
+package sys_utils
+
+import (
+	"errors"
+	"image"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+var (
+	dwmapi                           = syscall.NewLazyDLL("dwmapi.dll")
+	procDwmRegisterThumbnail         = dwmapi.NewProc("DwmRegisterThumbnail")
+	procDwmUnregisterThumbnail       = dwmapi.NewProc("DwmUnregisterThumbnail")
+	procDwmUpdateThumbnailProperties = dwmapi.NewProc("DwmUpdateThumbnailProperties")
+	procDwmFlush                     = dwmapi.NewProc("DwmFlush")
+	procPrintWindow                  = user32.NewProc("PrintWindow")
+	procDefWindowProcW               = user32.NewProc("DefWindowProcW")
+)
+
+// windowCaptureStrategies 默认的窗口截图策略，按尝试顺序排列：
+// PrintWindow(PW_RENDERFULLCONTENT) → PrintWindow(0) → 屏幕 BitBlt
+// DWM 缩略图不在默认链中：它只能合成到屏幕上可见的宿主窗口，无法离屏渲染，
+// 后台定时截图时宿主窗口会周期性地闪现在用户正在使用的窗口上方；
+// 三种方式均得到全黑图像时本次截图失败（不会保存黑帧），需要时可选择 win32_dwm 后端
+var windowCaptureStrategies = []CaptureStrategy{
+	{Name: "printwindow_full", Capture: func(w WindowInfo) (*image.RGBA, error) {
+		const PW_RENDERFULLCONTENT = 0x00000002
+		return printWindowCapture(w.hwnd(), PW_RENDERFULLCONTENT)
+	}},
+	{Name: "printwindow", Capture: func(w WindowInfo) (*image.RGBA, error) {
+		return printWindowCapture(w.hwnd(), 0)
+	}},
+	{Name: "bitblt", Capture: func(w WindowInfo) (*image.RGBA, error) {
+		return CaptureScreenRect(GetWindowBounds(w.hwnd()))
+	}},
+}
+
+// dwmThumbnailStrategy 通过 DWM 缩略图截取被遮挡的窗口；会在屏幕上短暂显示宿主窗口，
+// 因此仅在选择 win32_dwm 后端时追加到策略链末尾
+var dwmThumbnailStrategy = CaptureStrategy{Name: "dwm_thumbnail", Capture: func(w WindowInfo) (*image.RGBA, error) {
+	return dwmThumbnailCapture(w.hwnd())
+}}
+
+var (
+	// windowCaptureChain win32 后端共享的窗口截图策略链
+	windowCaptureChain = NewCaptureChain(windowCaptureStrategies...)
+	// windowCaptureChainDWM win32_dwm 后端的策略链：默认策略失败后再尝试 DWM 缩略图
+	windowCaptureChainDWM = NewCaptureChain(append(append([]CaptureStrategy(nil), windowCaptureStrategies...), dwmThumbnailStrategy)...)
+)
+
+// dwmThumbnailProperties 对应 DWM_THUMBNAIL_PROPERTIES
+type dwmThumbnailProperties struct {
+	DwFlags               uint32
+	RcDestination         win.RECT
+	RcSource              win.RECT
+	Opacity               byte
+	FVisible              int32
+	FSourceClientAreaOnly int32
+}
+
+const thumbHostClass = "CronShotThumbHost"
+
+var (
+	thumbClassOnce sync.Once
+	thumbClassErr  error
+)
+
+// registerThumbHostClass 注册宿主窗口类；窗口类在进程内共享，只注册一次
+func registerThumbHostClass() error {
+	thumbClassOnce.Do(func() {
+		className, _ := syscall.UTF16PtrFromString(thumbHostClass)
+		var wc win.WNDCLASSEX
+		wc.CbSize = uint32(unsafe.Sizeof(wc))
+		wc.LpfnWndProc = procDefWindowProcW.Addr()
+		wc.HInstance = win.GetModuleHandle(nil)
+		wc.LpszClassName = className
+		if win.RegisterClassEx(&wc) == 0 {
+			thumbClassErr = errors.New("RegisterClassEx failed")
+		}
+	})
+	return thumbClassErr
+}
+
+// dwmThumbnailCapture 在窗口所在位置创建置顶的透明宿主窗口，
+// 让 DWM 将目标窗口的缩略图合成到宿主上，再从屏幕复制该区域
+// 适用于被遮挡且 PrintWindow 无法渲染的窗口；DWM 不合成屏幕外或隐藏的窗口，
+// 宿主窗口会在截图期间（约 50ms）短暂可见，因此该策略需通过 win32_dwm 后端显式启用
+func dwmThumbnailCapture(hwnd win.HWND) (*image.RGBA, error) {
+	if err := procDwmRegisterThumbnail.Find(); err != nil {
+		return nil, err
+	}
+	bounds := getWindowRect(hwnd)
+	if bounds.Empty() {
+		return nil, errors.New("empty window rectangle")
+	}
+	// 窗口与缩略图均与创建线程绑定
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := registerThumbHostClass(); err != nil {
+		return nil, err
+	}
+	hInst := win.GetModuleHandle(nil)
+	className, _ := syscall.UTF16PtrFromString(thumbHostClass)
+	host := win.CreateWindowEx(
+		win.WS_EX_TOOLWINDOW|win.WS_EX_TOPMOST|win.WS_EX_NOACTIVATE|win.WS_EX_TRANSPARENT,
+		className, nil, win.WS_POPUP,
+		int32(bounds.Min.X), int32(bounds.Min.Y), int32(bounds.Dx()), int32(bounds.Dy()),
+		0, 0, hInst, nil,
+	)
+	if host == 0 {
+		return nil, errors.New("CreateWindowEx failed")
+	}
+	defer win.DestroyWindow(host)
+	win.ShowWindow(host, win.SW_SHOWNOACTIVATE)
+
+	var thumb uintptr
+	r, _, _ := procDwmRegisterThumbnail.Call(uintptr(host), uintptr(hwnd), uintptr(unsafe.Pointer(&thumb)))
+	if r != 0 {
+		return nil, syscall.Errno(r)
+	}
+	defer procDwmUnregisterThumbnail.Call(thumb)
+
+	const (
+		DWM_TNP_RECTDESTINATION = 0x1
+		DWM_TNP_OPACITY         = 0x4
+		DWM_TNP_VISIBLE         = 0x8
+	)
+	props := dwmThumbnailProperties{
+		DwFlags:       DWM_TNP_RECTDESTINATION | DWM_TNP_OPACITY | DWM_TNP_VISIBLE,
+		RcDestination: win.RECT{Right: int32(bounds.Dx()), Bottom: int32(bounds.Dy())},
+		Opacity:       255,
+		FVisible:      1,
+	}
+	r, _, _ = procDwmUpdateThumbnailProperties.Call(thumb, uintptr(unsafe.Pointer(&props)))
+	if r != 0 {
+		return nil, syscall.Errno(r)
+	}
+	// 处理宿主窗口的挂起消息并等待 DWM 完成一次合成
+	var msg win.MSG
+	for win.PeekMessage(&msg, host, 0, 0, win.PM_REMOVE) {
+		win.TranslateMessage(&msg)
+		win.DispatchMessage(&msg)
+	}
+	procDwmFlush.Call()
+	time.Sleep(50 * time.Millisecond)
+	img, err := CaptureScreenRect(bounds)
+	if err != nil {
+		return nil, err
+	}
+	return cropToFrame(hwnd, bounds, img), nil
+}
@@ -0,0 +1,6 @@
+//go:build !windows
+
+package sys_utils
+
+// AttachParentConsole 在非 Windows 平台上无需处理，标准输出始终连接到终端
+func AttachParentConsole() error { return nil }
@@ -0,0 +1,26 @@
+package sys_utils
+
+import (
+	"os"
+)
+
+var procAttachConsole = kernel32.NewProc("AttachConsole")
+
+// attachParentProcess 对应 ATTACH_PARENT_PROCESS（(DWORD)-1）
+const attachParentProcess = ^uint32(0)
+
+// AttachParentConsole 将标准输出/错误连接到启动本进程的控制台
+// 以 -H=windowsgui 构建的程序默认没有控制台，命令行子命令需要借此输出结果
+func AttachParentConsole() error {
+	r, _, err := procAttachConsole.Call(uintptr(attachParentProcess))
+	if r == 0 {
+		return err
+	}
+	out, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	os.Stdout = out
+	os.Stderr = out
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package sys_utils
+
+import "errors"
+
+// EnableDPIAwareness 非 Windows 平台由图形驱动自行处理缩放
+func EnableDPIAwareness() error { return errors.ErrUnsupported }
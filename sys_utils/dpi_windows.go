@@ -0,0 +1,73 @@
+package sys_utils
+
+import (
+	"errors"
+	"image"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+var (
+	shcore                            = syscall.NewLazyDLL("shcore.dll")
+	procSetProcessDpiAwarenessContext = user32.NewProc("SetProcessDpiAwarenessContext")
+	procSetProcessDpiAwareness        = shcore.NewProc("SetProcessDpiAwareness")
+	procSetProcessDPIAware            = user32.NewProc("SetProcessDPIAware")
+	procDwmGetWindowAttribute         = dwmapi.NewProc("DwmGetWindowAttribute")
+)
+
+// EnableDPIAwareness 将进程设置为按显示器 DPI 感知，使窗口坐标与截图均为物理像素
+// 依次尝试 Per-Monitor V2（Win10 1703+）、Per-Monitor（Win8.1+）与系统级 DPI 感知
+// 若进程已被设置过（例如由图形驱动设置），返回的错误可以忽略
+func EnableDPIAwareness() error {
+	if procSetProcessDpiAwarenessContext.Find() == nil {
+		const DPI_AWARENESS_CONTEXT_PER_MONITOR_AWARE_V2 = ^uintptr(3) // (DPI_AWARENESS_CONTEXT)-4
+		if r, _, _ := procSetProcessDpiAwarenessContext.Call(DPI_AWARENESS_CONTEXT_PER_MONITOR_AWARE_V2); r != 0 {
+			return nil
+		}
+	}
+	if procSetProcessDpiAwareness.Find() == nil {
+		const PROCESS_PER_MONITOR_DPI_AWARE = 2
+		if r, _, _ := procSetProcessDpiAwareness.Call(PROCESS_PER_MONITOR_DPI_AWARE); r == 0 {
+			return nil
+		}
+	}
+	if r, _, _ := procSetProcessDPIAware.Call(); r != 0 {
+		return nil
+	}
+	return errors.New("failed to enable DPI awareness")
+}
+
+// GetWindowDPI 返回窗口所在显示器的 DPI（系统不支持时返回设备 DPI）
+func GetWindowDPI(hwnd win.HWND) uint32 {
+	return win.GetDpiForWindow(hwnd)
+}
+
+// getWindowRect 返回 GetWindowRect 的原始矩形（Win10 下包含不可见的阴影边框）
+func getWindowRect(hwnd win.HWND) image.Rectangle {
+	var rect win.RECT
+	win.GetWindowRect(hwnd, &rect)
+	return image.Rect(int(rect.Left), int(rect.Top), int(rect.Right), int(rect.Bottom))
+}
+
+// getExtendedFrameBounds 通过 DWMWA_EXTENDED_FRAME_BOUNDS 获取窗口可见边框矩形
+// DWM 未启用或调用失败时返回 false
+func getExtendedFrameBounds(hwnd win.HWND) (image.Rectangle, bool) {
+	if procDwmGetWindowAttribute.Find() != nil {
+		return image.Rectangle{}, false
+	}
+	const DWMWA_EXTENDED_FRAME_BOUNDS = 9
+	var rect win.RECT
+	r, _, _ := procDwmGetWindowAttribute.Call(
+		uintptr(hwnd),
+		DWMWA_EXTENDED_FRAME_BOUNDS,
+		uintptr(unsafe.Pointer(&rect)),
+		unsafe.Sizeof(rect),
+	)
+	if r != 0 {
+		return image.Rectangle{}, false
+	}
+	b := image.Rect(int(rect.Left), int(rect.Top), int(rect.Right), int(rect.Bottom))
+	return b, !b.Empty()
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package sys_utils
+
+import "errors"
+
+// PickFolder 非 Windows 平台没有原生文件夹选择对话框
+func PickFolder() (string, error) { return "", errors.ErrUnsupported }
@@ -0,0 +1,42 @@
+//go:build !windows
+
+package sys_utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GetPicturesFolderWithFallback 优先返回 XDG 图片目录，失败时回退到用户家目录下的 Pictures
+func GetPicturesFolderWithFallback() string {
+	home, _ := os.UserHomeDir()
+	if p := xdgUserDir("XDG_PICTURES_DIR", home); p != "" {
+		return p
+	}
+	return filepath.Join(home, "Pictures")
+}
+
+// xdgUserDir 依次从环境变量与 ~/.config/user-dirs.dirs 读取 XDG 用户目录
+func xdgUserDir(key, home string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	cfg, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(cfg, "user-dirs.dirs"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, key+"=") {
+			continue
+		}
+		v := strings.Trim(strings.TrimPrefix(line, key+"="), `"`)
+		return strings.ReplaceAll(v, "$HOME", home)
+	}
+	return ""
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package sys_utils
+
+import (
+	"errors"
+	"time"
+)
+
+// GetIdleDuration 非 Windows 平台暂不支持空闲检测
+func GetIdleDuration() (time.Duration, error) { return 0, errors.ErrUnsupported }
+
+// IsSessionLocked 非 Windows 平台暂不支持锁屏检测，始终返回 false
+func IsSessionLocked() bool { return false }
+
+// IsScreenSaverRunning 非 Windows 平台暂不支持屏保检测，始终返回 false
+func IsScreenSaverRunning() bool { return false }
@@ -0,0 +1,58 @@
+package sys_utils
+
+import (
+	"errors"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	kernel32                  = syscall.NewLazyDLL("kernel32.dll")
+	procGetLastInputInfo      = user32.NewProc("GetLastInputInfo")
+	procGetTickCount          = kernel32.NewProc("GetTickCount")
+	procOpenInputDesktop      = user32.NewProc("OpenInputDesktop")
+	procSwitchDesktop         = user32.NewProc("SwitchDesktop")
+	procCloseDesktop          = user32.NewProc("CloseDesktop")
+	procSystemParametersInfoW = user32.NewProc("SystemParametersInfoW")
+)
+
+type lastInputInfo struct {
+	CbSize uint32
+	DwTime uint32
+}
+
+// GetIdleDuration 返回距离最后一次键盘/鼠标输入的时长
+func GetIdleDuration() (time.Duration, error) {
+	var li lastInputInfo
+	li.CbSize = uint32(unsafe.Sizeof(li))
+	r, _, _ := procGetLastInputInfo.Call(uintptr(unsafe.Pointer(&li)))
+	if r == 0 {
+		return 0, errors.New("GetLastInputInfo failed")
+	}
+	now, _, _ := procGetTickCount.Call()
+	// 使用 uint32 减法处理 GetTickCount 约 49.7 天的回绕
+	elapsed := uint32(now) - li.DwTime
+	return time.Duration(elapsed) * time.Millisecond, nil
+}
+
+// IsSessionLocked 判断当前会话是否处于锁屏状态
+// 锁屏时输入桌面切换为 Winlogon 桌面，SwitchDesktop 对其调用会失败
+func IsSessionLocked() bool {
+	const DESKTOP_SWITCHDESKTOP = 0x0100
+	h, _, _ := procOpenInputDesktop.Call(0, 0, DESKTOP_SWITCHDESKTOP)
+	if h == 0 {
+		return true
+	}
+	defer procCloseDesktop.Call(h)
+	r, _, _ := procSwitchDesktop.Call(h)
+	return r == 0
+}
+
+// IsScreenSaverRunning 判断屏幕保护程序是否正在运行
+func IsScreenSaverRunning() bool {
+	const SPI_GETSCREENSAVERRUNNING = 0x0072
+	var running int32
+	r, _, _ := procSystemParametersInfoW.Call(SPI_GETSCREENSAVERRUNNING, 0, uintptr(unsafe.Pointer(&running)), 0)
+	return r != 0 && running != 0
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package sys_utils
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// OpenFolder 使用系统默认文件管理器打开指定文件夹（macOS 为 open，其它为 xdg-open）
+func OpenFolder(path string) error {
+	name := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		name = "open"
+	}
+	cmd := exec.Command(name, path)
+	return cmd.Start()
+}
@@ -0,0 +1,46 @@
+package sys_utils
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cron-shot/utils"
+)
+
+// SaveCronShot 保存截图到 根目录\\进程名\\(固定文件夹)\\规则文件夹 下
+func SaveCronShot(img *image.RGBA, root string, processName, fixedFolder, folderName string, t time.Time) (string, error) {
+	return SaveCronShotWithMeta(img, root, processName, fixedFolder, folderName, t, nil)
+}
+
+// CronShotPath 返回截图的存储目录与文件路径，不创建任何文件
+// folderName 可包含 “/” 分隔的多级文件夹（由文件夹模板生成）
+func CronShotPath(root string, processName, fixedFolder, folderName string, t time.Time) (string, string) {
+	proc := utils.SanitizeProcessName(processName)
+	sub := utils.SanitizeFolderPath(folderName)
+	dir := filepath.Join(root, proc, sub)
+	if fixedFolder != "" {
+		fix := utils.SanitizeFolderName(fixedFolder)
+		dir = filepath.Join(root, proc, fix, sub)
+	}
+	name := t.Format("20060102_150405.000") + ".png"
+	return dir, filepath.Join(dir, name)
+}
+
+// SaveCronShotWithMeta 与 SaveCronShot 相同，并将 meta 作为 PNG 文本块写入文件
+func SaveCronShotWithMeta(img *image.RGBA, root string, processName, fixedFolder, folderName string, t time.Time, meta map[string]string) (string, error) {
+	dir, path := CronShotPath(root, processName, fixedFolder, folderName, t)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := utils.EncodePNGWithText(f, img, meta); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,79 @@
+package sys_utils
+
+import (
+	"errors"
+	"image"
+	"unsafe"
+
+	"cron-shot/utils"
+
+	"github.com/lxn/win"
+)
+
+// printWindowCapture 使用 PrintWindow 将窗口渲染至兼容位图
+// PrintWindow 按 GetWindowRect 渲染（含不可见阴影边框），渲染后裁剪到 DWM 扩展边框
+func printWindowCapture(hwnd win.HWND, flags uintptr) (*image.RGBA, error) {
+	rect := getWindowRect(hwnd)
+	width := rect.Dx()
+	height := rect.Dy()
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("empty window rectangle")
+	}
+	hdcScreen := win.GetDC(0)
+	defer win.ReleaseDC(0, hdcScreen)
+	hdcMem := win.CreateCompatibleDC(hdcScreen)
+	defer win.DeleteDC(hdcMem)
+	hbm := win.CreateCompatibleBitmap(hdcScreen, int32(width), int32(height))
+	defer win.DeleteObject(win.HGDIOBJ(hbm))
+	win.SelectObject(hdcMem, win.HGDIOBJ(hbm))
+	r, _, _ := procPrintWindow.Call(uintptr(hwnd), uintptr(hdcMem), flags)
+	if r == 0 {
+		return nil, errors.New("PrintWindow failed")
+	}
+	img := bitmapToRGBA(hdcMem, hbm, width, height)
+	return cropToFrame(hwnd, rect, img), nil
+}
+
+// cropToFrame 将按 GetWindowRect 截取的图像裁剪到窗口可见边框
+func cropToFrame(hwnd win.HWND, windowRect image.Rectangle, img *image.RGBA) *image.RGBA {
+	frame, ok := getExtendedFrameBounds(hwnd)
+	if !ok {
+		return img
+	}
+	return utils.CropRGBA(img, utils.FrameCropRect(windowRect, frame))
+}
+
+// bitmapToRGBA 读取兼容位图的像素（BGRA 自上而下）并转换为 RGBA 图像
+func bitmapToRGBA(hdc win.HDC, hbm win.HBITMAP, width, height int) *image.RGBA {
+	var bmi win.BITMAPINFO
+	bmi.BmiHeader.BiSize = uint32(unsafe.Sizeof(bmi.BmiHeader))
+	bmi.BmiHeader.BiWidth = int32(width)
+	bmi.BmiHeader.BiHeight = -int32(height)
+	bmi.BmiHeader.BiPlanes = 1
+	bmi.BmiHeader.BiBitCount = 32
+	bmi.BmiHeader.BiCompression = win.BI_RGB
+	stride := width * 4
+	buf := make([]byte, stride*height)
+	win.GetDIBits(hdc, hbm, 0, uint32(height), &buf[0], &bmi, win.DIB_RGB_COLORS)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	pi := 0
+	for y := 0; y < height; y++ {
+		row := y * stride
+		for x := 0; x < width; x++ {
+			i := row + x*4
+			b := buf[i+0]
+			g := buf[i+1]
+			r := buf[i+2]
+			a := buf[i+3]
+			if a == 0 {
+				a = 255
+			}
+			img.Pix[pi+0] = r
+			img.Pix[pi+1] = g
+			img.Pix[pi+2] = b
+			img.Pix[pi+3] = a
+			pi += 4
+		}
+	}
+	return img
+}
@@ -11,10 +11,8 @@ import (
 	"github.com/lxn/win"
 )
 
-type WindowInfo struct {
-	Title string
-	HWND  win.HWND
-}
+// hwnd 返回窗口句柄
+func (w WindowInfo) hwnd() win.HWND { return win.HWND(w.Handle) }
 
 // 说明：使用单例 EnumWindows 回调并通过包级上下文传参，避免频繁 syscall.NewCallback 导致崩溃
 var (
@@ -85,7 +83,7 @@ func enumCallbackDetailed(hwnd win.HWND, lParam uintptr) uintptr {
 			pName := syscall.UTF16ToString(nameBuf[:])
 			if strings.EqualFold(pName, enumTargetDetailed) {
 				if enumOutDetailed != nil {
-					*enumOutDetailed = append(*enumOutDetailed, WindowInfo{Title: title, HWND: hwnd})
+					*enumOutDetailed = append(*enumOutDetailed, WindowInfo{Title: title, Handle: uintptr(hwnd), PID: pid, Process: pName})
 				}
 			}
 		}
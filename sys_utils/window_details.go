@@ -0,0 +1,48 @@
+package sys_utils
+
+import (
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// WindowDetails 描述规则匹配用到的窗口扩展属性
+// ClassName: 窗口类名（X11 下为 WM_CLASS 的类名部分）；ExePath: 可执行文件完整路径；
+// CmdLine: 进程命令行；ParentProcess: 父进程名
+type WindowDetails struct {
+	ClassName     string
+	ExePath       string
+	CmdLine       string
+	ParentProcess string
+}
+
+// WindowClassProvider 由能够读取窗口类名的后端实现
+type WindowClassProvider interface {
+	WindowClass(w WindowInfo) string
+}
+
+// GetWindowDetails 查询窗口的扩展属性；类名由后端提供，进程相关属性通过 gopsutil 读取
+// 查询失败的字段保持为空
+func GetWindowDetails(b CaptureBackend, w WindowInfo) WindowDetails {
+	var d WindowDetails
+	if cp, ok := b.(WindowClassProvider); ok {
+		d.ClassName = cp.WindowClass(w)
+	}
+	if w.PID == 0 {
+		return d
+	}
+	p, err := process.NewProcess(int32(w.PID))
+	if err != nil {
+		return d
+	}
+	if exe, err := p.Exe(); err == nil {
+		d.ExePath = exe
+	}
+	if cmd, err := p.Cmdline(); err == nil {
+		d.CmdLine = cmd
+	}
+	if parent, err := p.Parent(); err == nil {
+		if name, err := parent.Name(); err == nil {
+			d.ParentProcess = name
+		}
+	}
+	return d
+}
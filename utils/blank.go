@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"image"
+	"math"
+)
+
+// IsAllBlack 判断图像是否几乎全黑（所有像素 RGB 分量均不超过 8）
+// 硬件加速窗口经 PrintWindow 渲染失败时常得到纯黑位图
+func IsAllBlack(img *image.RGBA) bool {
+	if img == nil || len(img.Pix) == 0 {
+		return true
+	}
+	const maxLevel = 8
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		if img.Pix[i] > maxLevel || img.Pix[i+1] > maxLevel || img.Pix[i+2] > maxLevel {
+			return false
+		}
+	}
+	return true
+}
+
+// 空白帧原因
+const (
+	BlankUniform     = "uniform"      // 纯色
+	BlankLowVariance = "low_variance" // 亮度方差接近零
+	BlankTransparent = "transparent"  // 大部分像素透明
+)
+
+// BlankOptions 空白帧判定参数
+// ColorTolerance: 各通道与首像素的最大差值，均不超过则视为纯色；
+// MinStdDev: 亮度标准差低于该值视为近零方差；
+// MaxTransparent: 透明像素（alpha=0）占比超过该值视为空白（0-1）
+type BlankOptions struct {
+	ColorTolerance int
+	MinStdDev      float64
+	MaxTransparent float64
+}
+
+// BlankOptionsFromTolerance 由单一容差值（0-64）构造判定参数
+func BlankOptionsFromTolerance(tol int) BlankOptions {
+	if tol < 0 {
+		tol = 0
+	} else if tol > 64 {
+		tol = 64
+	}
+	return BlankOptions{ColorTolerance: tol, MinStdDev: float64(tol) / 2, MaxTransparent: 0.95}
+}
+
+// DetectBlankFrame 判断图像是否为空白帧，返回是否空白与原因
+// 大图按步长采样（约 256x256 个点），避免逐像素遍历
+func DetectBlankFrame(img *image.RGBA, opt BlankOptions) (bool, string) {
+	if img == nil || img.Bounds().Empty() {
+		return true, BlankUniform
+	}
+	b := img.Bounds()
+	stepX := b.Dx()/256 + 1
+	stepY := b.Dy()/256 + 1
+	first := img.RGBAAt(b.Min.X, b.Min.Y)
+	uniform := true
+	var n, transparent int
+	var sum, sumSq float64
+	for y := b.Min.Y; y < b.Max.Y; y += stepY {
+		for x := b.Min.X; x < b.Max.X; x += stepX {
+			o := img.RGBAAt(x, y)
+			n++
+			if o.A == 0 {
+				transparent++
+			}
+			if uniform && (absDiff(o.R, first.R) > opt.ColorTolerance || absDiff(o.G, first.G) > opt.ColorTolerance || absDiff(o.B, first.B) > opt.ColorTolerance) {
+				uniform = false
+			}
+			g := 0.2126*float64(o.R) + 0.7152*float64(o.G) + 0.0722*float64(o.B)
+			sum += g
+			sumSq += g * g
+		}
+	}
+	if opt.MaxTransparent > 0 && float64(transparent)/float64(n) >= opt.MaxTransparent {
+		return true, BlankTransparent
+	}
+	if uniform {
+		return true, BlankUniform
+	}
+	mean := sum / float64(n)
+	variance := sumSq/float64(n) - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	if math.Sqrt(variance) < opt.MinStdDev {
+		return true, BlankLowVariance
+	}
+	return false, ""
+}
+
+// absDiff 返回两个通道值的差的绝对值
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
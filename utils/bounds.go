@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"image"
+	"image/draw"
+)
+
+// DefaultDPI 为 100% 缩放时的 DPI
+const DefaultDPI = 96
+
+// DPIScale 将 DPI 换算为缩放比例（96 → 1.0，144 → 1.5）；0 视为默认 DPI
+func DPIScale(dpi uint32) float64 {
+	if dpi == 0 {
+		return 1
+	}
+	return float64(dpi) / DefaultDPI
+}
+
+// FrameCropRect 计算可见边框在窗口位图中的区域
+// windowRect 为 GetWindowRect 结果（含不可见阴影边框），frameRect 为 DWM 扩展边框；
+// 返回相对 windowRect 左上角的裁剪区域；frameRect 为空或与窗口不相交时返回整个窗口
+func FrameCropRect(windowRect, frameRect image.Rectangle) image.Rectangle {
+	full := image.Rect(0, 0, windowRect.Dx(), windowRect.Dy())
+	if frameRect.Empty() {
+		return full
+	}
+	r := frameRect.Sub(windowRect.Min).Intersect(full)
+	if r.Empty() {
+		return full
+	}
+	return r
+}
+
+// CropRGBA 复制图像中的指定区域，返回以 (0,0) 为原点的新图像
+// 区域超出图像时按交集裁剪；交集为空时返回原图
+func CropRGBA(img *image.RGBA, r image.Rectangle) *image.RGBA {
+	r = r.Add(img.Bounds().Min).Intersect(img.Bounds())
+	if r.Empty() || r == img.Bounds() {
+		return img
+	}
+	out := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(out, out.Bounds(), img, r.Min, draw.Src)
+	return out
+}
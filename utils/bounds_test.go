@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDPIScale(t *testing.T) {
+	tests := []struct {
+		dpi  uint32
+		want float64
+	}{
+		{0, 1},
+		{96, 1},
+		{120, 1.25},
+		{144, 1.5},
+		{192, 2},
+	}
+	for _, tt := range tests {
+		if got := DPIScale(tt.dpi); got != tt.want {
+			t.Errorf("DPIScale(%d) = %v, want %v", tt.dpi, got, tt.want)
+		}
+	}
+}
+
+func TestFrameCropRect(t *testing.T) {
+	tests := []struct {
+		name   string
+		window image.Rectangle
+		frame  image.Rectangle
+		want   image.Rectangle
+	}{
+		{
+			// Windows 10/11 的窗口矩形左右下三边各含 7px 不可见阴影边框
+			name:   "dwm frame inset at 100%",
+			window: image.Rect(93, 100, 1107, 807),
+			frame:  image.Rect(100, 100, 1100, 800),
+			want:   image.Rect(7, 0, 1007, 700),
+		},
+		{
+			// 125% 缩放时阴影边框为 9px（物理像素）
+			name:   "dwm frame inset at 125%",
+			window: image.Rect(91, 100, 1359, 984),
+			frame:  image.Rect(100, 100, 1350, 975),
+			want:   image.Rect(9, 0, 1259, 875),
+		},
+		{
+			// 150% 缩放时阴影边框为 11px
+			name:   "dwm frame inset at 150%",
+			window: image.Rect(139, 150, 1661, 1211),
+			frame:  image.Rect(150, 150, 1650, 1200),
+			want:   image.Rect(11, 0, 1511, 1050),
+		},
+		{
+			name:   "monitor left of primary",
+			window: image.Rect(-1927, 200, -913, 907),
+			frame:  image.Rect(-1920, 200, -920, 900),
+			want:   image.Rect(7, 0, 1007, 700),
+		},
+		{
+			name:   "monitor above primary",
+			window: image.Rect(-7, -1080, 1927, -33),
+			frame:  image.Rect(0, -1080, 1920, -40),
+			want:   image.Rect(7, 0, 1927, 1040),
+		},
+		{
+			name:   "monitor offset to the right",
+			window: image.Rect(2553, 33, 3207, 547),
+			frame:  image.Rect(2560, 40, 3200, 540),
+			want:   image.Rect(7, 7, 647, 507),
+		},
+		{
+			name:   "no frame",
+			window: image.Rect(-500, -500, -100, -200),
+			frame:  image.Rectangle{},
+			want:   image.Rect(0, 0, 400, 300),
+		},
+		{
+			name:   "frame outside window",
+			window: image.Rect(0, 0, 400, 300),
+			frame:  image.Rect(1000, 1000, 1200, 1200),
+			want:   image.Rect(0, 0, 400, 300),
+		},
+		{
+			name:   "frame larger than window is clipped",
+			window: image.Rect(10, 10, 410, 310),
+			frame:  image.Rect(0, 0, 420, 320),
+			want:   image.Rect(0, 0, 400, 300),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FrameCropRect(tt.window, tt.frame); got != tt.want {
+				t.Errorf("FrameCropRect(%v, %v) = %v, want %v", tt.window, tt.frame, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCropRGBA(t *testing.T) {
+	// 每个像素的 R/G 分量记录其坐标，便于检查裁剪位置
+	newImage := func(r image.Rectangle) *image.RGBA {
+		img := image.NewRGBA(r)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				img.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y), A: 255})
+			}
+		}
+		return img
+	}
+	tests := []struct {
+		name     string
+		src      image.Rectangle
+		crop     image.Rectangle
+		wantSize image.Point
+		wantAt00 color.RGBA
+		same     bool
+	}{
+		{
+			name:     "inset",
+			src:      image.Rect(0, 0, 20, 10),
+			crop:     image.Rect(7, 0, 13, 3),
+			wantSize: image.Pt(6, 3),
+			wantAt00: color.RGBA{R: 7, G: 0, A: 255},
+		},
+		{
+			name:     "crop relative to non-zero origin",
+			src:      image.Rect(5, 5, 25, 15),
+			crop:     image.Rect(2, 3, 6, 6),
+			wantSize: image.Pt(4, 3),
+			wantAt00: color.RGBA{R: 7, G: 8, A: 255},
+		},
+		{
+			name:     "clipped to image",
+			src:      image.Rect(0, 0, 10, 10),
+			crop:     image.Rect(6, 6, 20, 20),
+			wantSize: image.Pt(4, 4),
+			wantAt00: color.RGBA{R: 6, G: 6, A: 255},
+		},
+		{
+			name: "full image returned as is",
+			src:  image.Rect(0, 0, 10, 10),
+			crop: image.Rect(0, 0, 10, 10),
+			same: true,
+		},
+		{
+			name: "empty intersection returned as is",
+			src:  image.Rect(0, 0, 10, 10),
+			crop: image.Rect(20, 20, 30, 30),
+			same: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := newImage(tt.src)
+			got := CropRGBA(src, tt.crop)
+			if tt.same {
+				if got != src {
+					t.Errorf("CropRGBA returned a copy, want the original image")
+				}
+				return
+			}
+			if got.Bounds() != (image.Rectangle{Max: tt.wantSize}) {
+				t.Errorf("bounds = %v, want origin-based size %v", got.Bounds(), tt.wantSize)
+			}
+			if c := got.RGBAAt(0, 0); c != tt.wantAt00 {
+				t.Errorf("pixel (0,0) = %v, want %v", c, tt.wantAt00)
+			}
+		})
+	}
+}
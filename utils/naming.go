@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"path/filepath"
 	"strings"
 	"unicode"
 
@@ -18,6 +19,32 @@ func SanitizeProcessName(name string) string {
 
 // SanitizeFolderName 清理文件夹名的非法字符与空白
 func SanitizeFolderName(name string) string {
+	out := cleanFolderName(name)
+	if out == "" {
+		return constants.TextUnknownName
+	}
+	return out
+}
+
+// SanitizeFolderPath 清理由 “/” 或 “\” 分隔的多级文件夹路径
+// 逐级清理非法字符，丢弃空白及 “.”、“..” 层级；全部为空时返回未知名称
+func SanitizeFolderPath(path string) string {
+	var parts []string
+	for _, seg := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' }) {
+		seg = cleanFolderName(seg)
+		if seg == "" || seg == "." || seg == ".." {
+			continue
+		}
+		parts = append(parts, seg)
+	}
+	if len(parts) == 0 {
+		return constants.TextUnknownName
+	}
+	return filepath.Join(parts...)
+}
+
+// cleanFolderName 移除文件夹名中的非法字符与空白，结果可能为空
+func cleanFolderName(name string) string {
 	s := strings.TrimSpace(name)
 	invalid := []rune{'<', '>', ':', '\\', '/', '|', '?', '*', '"'}
 	m := make(map[rune]struct{}, len(invalid))
@@ -34,9 +61,5 @@ func SanitizeFolderName(name string) string {
 		}
 		b.WriteRune(r)
 	}
-	out := strings.TrimSpace(b.String())
-	if out == "" {
-		return constants.TextUnknownName
-	}
-	return out
+	return strings.TrimSpace(b.String())
 }
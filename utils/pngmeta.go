@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"sort"
+)
+
+// EncodePNGWithText 编码 PNG 并在 IHDR 之后写入 iTXt 文本块（UTF-8，不压缩）
+// 用于在截图文件中记录窗口、DPI 缩放等元数据；text 为空时等同 png.Encode
+func EncodePNGWithText(w io.Writer, img image.Image, text map[string]string) error {
+	if len(text) == 0 {
+		return png.Encode(w, img)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	// PNG 签名 8 字节 + IHDR 块（长度4 + 类型4 + 数据13 + CRC4）
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+	if len(data) < ihdrEnd || string(data[12:16]) != "IHDR" {
+		return errors.New("unexpected png layout")
+	}
+	if _, err := w.Write(data[:ihdrEnd]); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(text))
+	for k := range text {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := writeITXt(w, k, text[k]); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(data[ihdrEnd:])
+	return err
+}
+
+// writeITXt 写入单个 iTXt 块：关键字\0 压缩标志 压缩方法 语言\0 翻译关键字\0 文本
+func writeITXt(w io.Writer, keyword, value string) error {
+	var body bytes.Buffer
+	body.WriteString(keyword)
+	body.Write([]byte{0, 0, 0, 0, 0})
+	body.WriteString(value)
+	var hdr [8]byte
+	binary.BigEndian.PutUint32(hdr[:4], uint32(body.Len()))
+	copy(hdr[4:], "iTXt")
+	crc := crc32.NewIEEE()
+	crc.Write(hdr[4:])
+	crc.Write(body.Bytes())
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	for _, b := range [][]byte{hdr[:], body.Bytes(), sum[:]} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
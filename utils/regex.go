@@ -1,24 +1,84 @@
 package utils
 
 import (
+	"strconv"
 	"strings"
+	"time"
 
 	"cron-shot/constants"
 
 	"github.com/dlclark/regexp2"
 )
 
+// RegexMatchTimeout 单次正则匹配的超时时间，防止回溯型表达式卡住截图循环
+const RegexMatchTimeout = 100 * time.Millisecond
+
+// CompileRegex 使用统一的正则引擎（regexp2，支持环视）编译表达式并设置匹配超时
+// 规则匹配、存储文件夹解析与界面校验均应通过此函数编译，保证行为一致
+func CompileRegex(pattern string) (*regexp2.Regexp, error) {
+	re, err := regexp2.Compile(pattern, 0)
+	if err != nil {
+		return nil, err
+	}
+	re.MatchTimeout = RegexMatchTimeout
+	return re, nil
+}
+
+// RegexMatch 判断 s 是否匹配；re 为空或匹配超时视为不匹配
+func RegexMatch(re *regexp2.Regexp, s string) bool {
+	if re == nil {
+		return false
+	}
+	ok, err := re.MatchString(s)
+	return err == nil && ok
+}
+
+// RegexGroups 返回 s 的首个匹配中各捕获组的值，格式为 “组名=值”（未命名组以序号为名）
+// 不包含整体匹配；未匹配或超时时返回空
+func RegexGroups(re *regexp2.Regexp, s string) []string {
+	if re == nil {
+		return nil
+	}
+	m, err := re.FindStringMatch(s)
+	if err != nil || m == nil {
+		return nil
+	}
+	var out []string
+	for _, g := range m.Groups()[1:] {
+		out = append(out, g.Name+"="+g.String())
+	}
+	return out
+}
+
 // ResolveStorageFolder 使用给定正则/文本解析窗口标题，返回文件夹名
 // 优先返回第一个捕获组，其次返回整体匹配；失败返回未知名称
 func ResolveStorageFolder(windowTitle, storageRule string) string {
+	return ResolveStorageFolderTemplate(windowTitle, storageRule, "")
+}
+
+// ResolveStorageFolderTemplate 与 ResolveStorageFolder 相同；template 不为空时按模板组合文件夹，
+// 见 ExpandFolderTemplate
+func ResolveStorageFolderTemplate(windowTitle, storageRule, template string) string {
 	rule := strings.TrimSpace(storageRule)
 	if rule == "" {
-		return SanitizeFolderName(windowTitle)
+		return ResolveStorageFolderWith(windowTitle, nil, template)
 	}
-	re, err := regexp2.Compile(rule, 0)
+	re, err := CompileRegex(rule)
 	if err != nil {
 		return constants.TextUnknownName
 	}
+	return ResolveStorageFolderWith(windowTitle, re, template)
+}
+
+// ResolveStorageFolderWith 使用已编译的存储规则解析窗口标题；re 为空时返回标题本身
+// template 不为空时按模板组合文件夹（存储规则未命中时捕获组均为空，由模板的备选值兜底）
+func ResolveStorageFolderWith(windowTitle string, re *regexp2.Regexp, template string) string {
+	if strings.TrimSpace(template) != "" {
+		return ExpandFolderTemplate(template, folderTemplateValues(windowTitle, re))
+	}
+	if re == nil {
+		return SanitizeFolderName(windowTitle)
+	}
 	m, err := re.FindStringMatch(windowTitle)
 	if err != nil || m == nil {
 		return constants.TextUnknownName
@@ -32,3 +92,106 @@ func ResolveStorageFolder(windowTitle, storageRule string) string {
 	}
 	return SanitizeFolderName(m.String())
 }
+
+// 模板内置变量
+const (
+	TemplateTitle = "title" // 完整窗口标题
+	TemplateMatch = "match" // 存储规则的整体匹配
+)
+
+// folderTemplateValues 收集模板可引用的值：窗口标题、整体匹配与各捕获组（按序号与组名）
+// 值为空字符串表示该组存在但未捕获到内容
+func folderTemplateValues(title string, re *regexp2.Regexp) map[string]string {
+	vals := map[string]string{TemplateTitle: title}
+	if re == nil {
+		return vals
+	}
+	vals[TemplateMatch] = ""
+	for _, name := range re.GetGroupNames() {
+		vals[name] = ""
+	}
+	for _, n := range re.GetGroupNumbers() {
+		vals[strconv.Itoa(n)] = ""
+	}
+	m, err := re.FindStringMatch(title)
+	if err != nil || m == nil {
+		return vals
+	}
+	vals[TemplateMatch] = m.String()
+	for i, g := range m.Groups() {
+		vals[g.Name] = g.String()
+		vals[strconv.Itoa(i)] = g.String()
+	}
+	return vals
+}
+
+// ExpandFolderTemplate 展开文件夹模板，“/” 分隔多级文件夹
+// {name} 引用捕获组（组名或序号）或内置变量 title/match；
+// {a|b|默认} 依次取第一个非空的值，不是组名或变量的备选项按字面文本使用；
+// 展开后为空的层级被省略，全部为空时返回未知名称
+func ExpandFolderTemplate(template string, values map[string]string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(template, '{')
+		if i < 0 {
+			b.WriteString(template)
+			break
+		}
+		j := strings.IndexByte(template[i:], '}')
+		if j < 0 {
+			b.WriteString(template)
+			break
+		}
+		b.WriteString(template[:i])
+		b.WriteString(expandTemplateField(template[i+1:i+j], values))
+		template = template[i+j+1:]
+	}
+	return SanitizeFolderPath(b.String())
+}
+
+// UnknownTemplateNames 返回模板中引用了不存在的捕获组或变量的名称（按出现顺序，不重复）
+// re 为存储规则（可为空，此时只有 title 可用）；多个备选项中的最后一项可以是字面默认值，不做检查
+func UnknownTemplateNames(template string, re *regexp2.Regexp) []string {
+	known := folderTemplateValues("", re)
+	var out []string
+	seen := map[string]bool{}
+	for {
+		i := strings.IndexByte(template, '{')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(template[i:], '}')
+		if j < 0 {
+			break
+		}
+		alts := strings.Split(template[i+1:i+j], "|")
+		if len(alts) > 1 {
+			alts = alts[:len(alts)-1]
+		}
+		for _, alt := range alts {
+			alt = strings.TrimSpace(alt)
+			if _, ok := known[alt]; !ok && !seen[alt] {
+				seen[alt] = true
+				out = append(out, alt)
+			}
+		}
+		template = template[i+j+1:]
+	}
+	return out
+}
+
+// expandTemplateField 返回字段中第一个非空的备选值（已清理非法字符，
+// 因此值中的 “/” 不会产生额外层级）
+func expandTemplateField(field string, values map[string]string) string {
+	for _, alt := range strings.Split(field, "|") {
+		alt = strings.TrimSpace(alt)
+		v, ok := values[alt]
+		if !ok {
+			v = alt
+		}
+		if v = cleanFolderName(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnknownTemplateNames(t *testing.T) {
+	re, err := CompileRegex(`(?<project>\w+) - (?<branch>\w+) - (\w+)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"{project}/{branch}", nil},
+		{"{project}/{3}/{0}/{title}/{match}", nil},
+		{"{projcet}", []string{"projcet"}},
+		{"{4}", []string{"4"}},
+		// 最后一个备选项是字面默认值
+		{"{branch|main}", nil},
+		{"{brnach|main}", []string{"brnach"}},
+		{"{projcet}/{projcet}/{branch|x|y}", []string{"projcet", "x"}},
+		{"plain/{ project }", nil},
+		{"unclosed/{project", nil},
+	}
+	for _, tt := range tests {
+		got := UnknownTemplateNames(tt.template, re)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("UnknownTemplateNames(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+	// 没有存储规则时只能引用标题
+	if got := UnknownTemplateNames("{title}/{match}/{1}", nil); strings.Join(got, ",") != "match,1" {
+		t.Errorf("without storage rule = %q, want [match 1]", got)
+	}
+}